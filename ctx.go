@@ -2,14 +2,21 @@ package fuego
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"io/fs"
 	"log/slog"
+	"mime"
+	"mime/multipart"
 	"net/http"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -36,6 +43,10 @@ type ctx[B any] interface {
 	//   	...
 	//   })
 	PathParam(name string) string
+	PathParamInt(name string, defaultValue int) int // If the path parameter is not an int, it returns the default given value. Use [Ctx.PathParamIntErr] if you want to know if the path parameter is erroneous.
+	PathParamIntErr(name string) (int, error)
+	PathParamUUID(name string, defaultValue uuid.UUID) uuid.UUID // If the path parameter is not an UUID, it returns the default given value. Use [Ctx.PathParamUUIDErr] if you want to know if the path parameter is erroneous.
+	PathParamUUIDErr(name string) (uuid.UUID, error)
 	PathParams() map[string]string
 	QueryParam(name string) string
 	QueryParamInt(name string, defaultValue int) int // If the query parameter does not exist or is not an int, it returns the default given value. Use [Ctx.QueryParamIntErr] if you want to know if the query parameter is erroneous.
@@ -44,6 +55,26 @@ type ctx[B any] interface {
 	QueryParamBoolErr(name string) (bool, error)
 	QueryParams() map[string]string
 
+	// FormFile returns the first file uploaded with the given form field name, in a multipart/form-data request.
+	// The limits set with [LimitFileUploads] are checked.
+	FormFile(name string) (multipart.File, *multipart.FileHeader, error)
+
+	// MultipartReader returns a reader to stream the parts of a multipart/form-data request,
+	// without storing the files in memory nor on disk. The limits set with [LimitFileUploads] are not checked.
+	MultipartReader() (*multipart.Reader, error)
+
+	// LastEventID returns the ID of the last Server-Sent Event received by the client, when it reconnects.
+	// It is read from the Last-Event-ID header. See [SSE].
+	LastEventID() string
+
+	// RequestID returns the ID of the request, read from the X-Request-ID header or generated.
+	// Empty if [WithRequestID] is not used.
+	RequestID() string
+
+	// Logger returns the logger of the request: the default logger, adding the ID of the request
+	// to the records if [WithRequestID] is used. The records of the default logger itself do not have the ID.
+	Logger() *slog.Logger
+
 	MainLang() string   // ex: fr. MainLang returns the main language of the request. It is the first language of the Accept-Language header. To get the main locale (ex: fr-CA), use [Ctx.MainLocale].
 	MainLocale() string // ex: en-US. MainLocale returns the main locale of the request. It is the first locale of the Accept-Language header. To get the main language (ex: en), use [Ctx.MainLang].
 
@@ -105,15 +136,39 @@ type ContextWithBody[Body any] struct {
 	ContextNoBody
 }
 
+// ContextWithParams is used when the controller reads its query parameters into a struct.
+// The struct fields are matched with the `query` tag, and support the `default`, `description` and `validate` tags.
+// They are documented in the OpenAPI spec as query parameters.
+// Fields with a `path` tag are read from the path parameters instead, and document their type in the OpenAPI spec.
+// Please do not use a pointer type as parameter.
+// Example:
+//
+//	type RecipesFilter struct {
+//		AuthorID uuid.UUID `path:"author_id"`
+//		Search   string    `query:"search" description:"Search in recipe names"`
+//		Limit    int       `query:"limit" default:"10" validate:"max=100"`
+//		Tags     []string  `query:"tags"`
+//		After    time.Time `query:"after"`
+//	}
+//
+//	fuego.Get(s, "/authors/{author_id}/recipes", func(c *fuego.ContextWithParams[RecipesFilter]) ([]Recipe, error) {
+//		filter, err := c.Params()
+//		...
+//	})
+type ContextWithParams[Params any] struct {
+	params *Params // Cache the params, as they are decoded, transformed and validated.
+	ContextNoBody
+}
+
 // ContextNoBody is used when the controller does not have a body.
 // It used as a base context for other Context types.
 type ContextNoBody struct {
-	request    *http.Request
-	response   http.ResponseWriter
-	pathParams map[string]string
+	request  *http.Request
+	response http.ResponseWriter
 
 	fs        fs.FS
 	templates *template.Template
+	logger    *slog.Logger
 
 	readOptions readOptions
 }
@@ -142,11 +197,13 @@ type readOptions struct {
 	DisallowUnknownFields bool
 	MaxBodySize           int64
 	LogBody               bool
+	deserializers         map[string]Deserializer // Deserializers by media type. Defaults to [defaultDeserializers] if nil.
 }
 
 var (
 	_ ctx[any]    = &ContextWithBody[any]{}    // Check that ContextWithBody[any] implements Ctx.
 	_ ctx[string] = &ContextWithBody[string]{} // Check that ContextWithBody[string] implements Ctx.
+	_ ctx[any]    = &ContextWithParams[any]{}  // Check that ContextWithParams[any] implements Ctx.
 	_ ctx[any]    = &ContextNoBody{}           // Check that ContextNoBody implements Ctx.
 	_ ctx[any]    = ContextNoBody{}            // Check that ContextNoBody implements Ctx.
 )
@@ -208,18 +265,93 @@ func (c ContextNoBody) Render(templateToExecute string, data any, layoutsGlobs .
 	return "", err
 }
 
-// PathParams returns the path parameters of the request.
+// PathParam returns the path parameter with the given name.
+// It is read from [http.Request.PathValue], so the route must be registered with a pattern like /recipes/{id}.
 func (c ContextNoBody) PathParam(name string) string {
-	param := c.pathParams[name]
+	param := c.request.PathValue(name)
 	if param == "" {
-		slog.Error("Path parameter might be invalid", "name", name, "valid parameters", c.pathParams)
+		slog.Error("Path parameter might be invalid", "name", name, "valid parameters", c.PathParams())
 	}
-	return param // TODO go1.22: get (*http.Request) PathValue(name)
+	return param
 }
 
-// PathParams returns the path parameters of the request.
+// PathParamIntErr returns the path parameter with the given name as an int.
+// If the path parameter is not an int, it returns a [PathParamInvalidTypeError], answered with a 400 status code.
+func (c ContextNoBody) PathParamIntErr(name string) (int, error) {
+	param := c.request.PathValue(name)
+	if param == "" {
+		return 0, PathParamNotFoundError{ParamName: name}
+	}
+
+	i, err := strconv.Atoi(param)
+	if err != nil {
+		return 0, PathParamInvalidTypeError{
+			ParamName:    name,
+			ParamValue:   param,
+			ExpectedType: "int",
+			Err:          err,
+		}
+	}
+
+	return i, nil
+}
+
+// PathParamInt returns the path parameter with the given name as an int.
+// If the path parameter is not an int, it returns the default value. Use [ContextNoBody.PathParamIntErr] to get the error.
+// The parameter is documented as a string in the OpenAPI spec: use [Route.WithPathParam], or a `path` tag in the params of a [ContextWithParams].
+func (c ContextNoBody) PathParamInt(name string, defaultValue int) int {
+	param, err := c.PathParamIntErr(name)
+	if err != nil {
+		return defaultValue
+	}
+
+	return param
+}
+
+// PathParamUUIDErr returns the path parameter with the given name as an UUID.
+// If the path parameter is not an UUID, it returns a [PathParamInvalidTypeError], answered with a 400 status code.
+func (c ContextNoBody) PathParamUUIDErr(name string) (uuid.UUID, error) {
+	param := c.request.PathValue(name)
+	if param == "" {
+		return uuid.Nil, PathParamNotFoundError{ParamName: name}
+	}
+
+	id, err := uuid.Parse(param)
+	if err != nil {
+		return uuid.Nil, PathParamInvalidTypeError{
+			ParamName:    name,
+			ParamValue:   param,
+			ExpectedType: "uuid",
+			Err:          err,
+		}
+	}
+
+	return id, nil
+}
+
+// PathParamUUID returns the path parameter with the given name as an UUID.
+// If the path parameter is not an UUID, it returns the default value. Use [ContextNoBody.PathParamUUIDErr] to get the error.
+// The parameter is documented as a string in the OpenAPI spec: use [Route.WithPathParam], or a `path` tag in the params of a [ContextWithParams].
+func (c ContextNoBody) PathParamUUID(name string, defaultValue uuid.UUID) uuid.UUID {
+	param, err := c.PathParamUUIDErr(name)
+	if err != nil {
+		return defaultValue
+	}
+
+	return param
+}
+
+// PathParams returns the path parameters of the request, as declared in the route pattern.
 func (c ContextNoBody) PathParams() map[string]string {
-	return nil
+	params := make(map[string]string)
+	for _, name := range parsePathParams(routePattern(c.request.Context())) {
+		name = strings.TrimSuffix(name, "...")
+		if name == "$" {
+			continue
+		}
+		params[name] = c.request.PathValue(name)
+	}
+	return params
 }
 
 type QueryParamNotFoundError struct {
@@ -314,6 +446,57 @@ func (c ContextNoBody) QueryParamBool(name string, defaultValue bool) bool {
 	return param
 }
 
+// FormFile returns the first file uploaded with the given form field name, in a multipart/form-data request.
+// The limits set with [LimitFileUploads] and [WithMaxBodySize] are checked.
+// The file must be closed by the caller.
+func (c ContextNoBody) FormFile(name string) (multipart.File, *multipart.FileHeader, error) {
+	form, err := parseMultipartForm(c.request, c.readOptions.MaxBodySize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := form.File[name]
+	if len(headers) == 0 {
+		return nil, nil, BadRequestError{Message: "file " + name + " not found in multipart form"}
+	}
+
+	file, err := headers[0].Open()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, headers[0], nil
+}
+
+// MultipartReader returns a reader to stream the parts of a multipart/form-data request,
+// without storing the files in memory nor on disk.
+// The limits set with [LimitFileUploads] are not checked, the caller is responsible for limiting the size of the parts.
+func (c ContextNoBody) MultipartReader() (*multipart.Reader, error) {
+	reader, err := c.request.MultipartReader()
+	if err != nil {
+		return nil, BadRequestError{Message: "cannot read multipart form: " + err.Error(), Err: err}
+	}
+	return reader, nil
+}
+
+// LastEventID returns the ID of the last Server-Sent Event received by the client, when it reconnects.
+// It is read from the Last-Event-ID header. See [SSE].
+func (c ContextNoBody) LastEventID() string {
+	return c.request.Header.Get("Last-Event-ID")
+}
+
+// RequestID returns the ID of the request, read from the X-Request-ID header or generated.
+// Empty if [WithRequestID] is not used.
+func (c ContextNoBody) RequestID() string {
+	return RequestIDFromContext(c.request.Context())
+}
+
+// Logger returns the logger of the request: the default logger, adding the ID of the request
+// to the records if [WithRequestID] is used. The records of the default logger itself do not have the ID.
+func (c ContextNoBody) Logger() *slog.Logger {
+	return requestLogger(c.logger, c.request.Context())
+}
+
 func (c ContextNoBody) MainLang() string {
 	return strings.Split(c.MainLocale(), "-")[0]
 }
@@ -365,21 +548,109 @@ func body[B any](c ContextNoBody) (B, error) {
 	timeDeserialize := time.Now()
 
 	var body B
-	var err error
-	switch c.request.Header.Get("Content-Type") {
-	case "text/plain":
-		s, errReadingString := readString[string](c.request.Context(), c.request.Body, c.readOptions)
-		body = any(s).(B)
-		err = errReadingString
-	case "application/x-www-form-urlencoded", "multipart/form-data":
-		body, err = readURLEncoded[B](c.request, c.readOptions)
-	case "application/json":
-		fallthrough
-	default:
-		body, err = readJSON[B](c.request.Context(), c.request.Body, c.readOptions)
-	}
-
-	c.response.Header().Add("Server-Timing", Timing{"deserialize", time.Since(timeDeserialize), "controller > deserialize"}.String())
+	_, endDeserialize := startSpan(c.request.Context(), "deserialize")
+	err := deserializeBody(c, &body)
+	endDeserialize(err)
+	addTiming(c.response, c.request, Timing{"deserialize", time.Since(timeDeserialize), "controller > deserialize"})
+	if err != nil {
+		return body, err
+	}
+	slog.Debug("Decoded body", "body", body)
 
-	return body, err
+	transformCtx, endTransform := startSpan(c.request.Context(), "transformIn")
+	body, err = transform(transformCtx, body)
+	endTransform(err)
+	if err != nil {
+		return body, err
+	}
+
+	err = validate(body)
+	if err != nil {
+		validationError := BadRequestError{Message: "cannot validate request body: " + err.Error(), Err: err}
+		// Keeps the details of the validation errors, ex: the fields of a [structValidationError].
+		var errorInfo ErrorWithInfo
+		if errors.As(err, &errorInfo) {
+			validationError.MoreInfo = errorInfo.Info()
+		}
+		return body, validationError
+	}
+
+	return body, nil
+}
+
+// deserializeBody decodes the request body with the deserializer registered for its Content-Type.
+// Media type parameters such as charset are ignored. If the Content-Type is not set, defaults to application/json.
+func deserializeBody(c ContextNoBody, body any) error {
+	mediaType := "application/json"
+	if contentType := c.request.Header.Get("Content-Type"); contentType != "" {
+		var err error
+		mediaType, _, err = mime.ParseMediaType(contentType)
+		if err != nil {
+			return BadRequestError{Message: "cannot parse Content-Type header: " + err.Error(), Err: err}
+		}
+	}
+
+	deserializers := c.readOptions.deserializers
+	if deserializers == nil {
+		deserializers = defaultDeserializers
+	}
+
+	deserialize, ok := deserializers[mediaType]
+	if !ok {
+		supported := make([]string, 0, len(deserializers))
+		for supportedMediaType := range deserializers {
+			supported = append(supported, supportedMediaType)
+		}
+		slices.Sort(supported)
+
+		return HTTPError{
+			StatusCode: http.StatusUnsupportedMediaType,
+			Message:    "unsupported media type: " + mediaType,
+			MoreInfo: map[string]any{
+				"supported": supported,
+			},
+		}
+	}
+
+	return deserialize(c.request, body, DeserializeOptions{
+		DisallowUnknownFields: c.readOptions.DisallowUnknownFields,
+	})
+}
+
+// Params returns the query parameters of the request, decoded into the Params struct.
+// If (*Params) implements [InTransformer], it will be transformed after deserialization.
+// It caches the result, so it can be called multiple times.
+func (c *ContextWithParams[Params]) Params() (Params, error) {
+	if c.params != nil {
+		return *c.params, nil
+	}
+
+	params, err := readQueryParams[Params](c.request, c.readOptions)
+	if err != nil {
+		return params, err
+	}
+	c.params = &params
+	return params, nil
+}
+
+// MustParams works like Params, but panics if there is an error.
+func (c *ContextWithParams[Params]) MustParams() Params {
+	params, err := c.Params()
+	if err != nil {
+		panic(err)
+	}
+	return params
+}
+
+// paramsType returns the type of the Params struct, used to generate the OpenAPI spec.
+// It does not dereference the receiver, so it can be called on a nil pointer.
+func (c *ContextWithParams[Params]) paramsType() reflect.Type {
+	return reflect.TypeOf((*Params)(nil)).Elem()
+}
+
+// newFromBase is used by [initContext] to create a ContextWithParams, whatever its Params type.
+func (c *ContextWithParams[Params]) newFromBase(baseContext ContextNoBody) any {
+	return &ContextWithParams[Params]{
+		ContextNoBody: baseContext,
+	}
 }
@@ -2,18 +2,21 @@ package fuego
 
 import (
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
 func TestContext_PathParam(t *testing.T) {
 	t.Run("can read path param", func(t *testing.T) {
-		t.Skip("TODO: coming in go1.22")
-
 		s := NewServer()
 		Get(s, "/foo/{id}", func(c ContextNoBody) (ans, error) {
 			return ans{Ans: c.PathParam("id")}, nil
@@ -24,7 +27,77 @@ func TestContext_PathParam(t *testing.T) {
 
 		s.Mux.ServeHTTP(w, r)
 
-		require.Equal(t, w.Body.String(), `{"ans":"123"}`)
+		require.Equal(t, `{"ans":"123"}`+"\n", w.Body.String())
+	})
+
+	t.Run("can read all path params", func(t *testing.T) {
+		s := NewServer()
+		Get(s, "/foo/{id}/bar/{name}", func(c ContextNoBody) (map[string]string, error) {
+			return c.PathParams(), nil
+		})
+
+		r := httptest.NewRequest("GET", "/foo/123/bar/john", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, `{"id":"123","name":"john"}`+"\n", w.Body.String())
+	})
+
+	t.Run("can read path param as int", func(t *testing.T) {
+		s := NewServer()
+		Get(s, "/foo/{id}", func(c ContextNoBody) (int, error) {
+			return c.PathParamIntErr("id")
+		})
+		Get(s, "/default/{id}", func(c ContextNoBody) (int, error) {
+			return c.PathParamInt("id", 42), nil
+		})
+
+		r := httptest.NewRequest("GET", "/foo/123", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, 200, w.Code)
+		require.Equal(t, "123\n", w.Body.String())
+
+		r = httptest.NewRequest("GET", "/foo/abc", nil)
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, 400, w.Code)
+		require.Contains(t, w.Body.String(), "path param id=abc is not of type int")
+
+		r = httptest.NewRequest("GET", "/default/abc", nil)
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, 200, w.Code)
+		require.Equal(t, "42\n", w.Body.String())
+	})
+
+	t.Run("can read path param as uuid", func(t *testing.T) {
+		s := NewServer()
+		Get(s, "/foo/{id}", func(c ContextNoBody) (uuid.UUID, error) {
+			return c.PathParamUUIDErr("id")
+		})
+
+		r := httptest.NewRequest("GET", "/foo/6ba7b810-9dad-11d1-80b4-00c04fd430c8", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, 200, w.Code)
+		require.Equal(t, `"6ba7b810-9dad-11d1-80b4-00c04fd430c8"`+"\n", w.Body.String())
+
+		r = httptest.NewRequest("GET", "/foo/123", nil)
+		w = httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, 400, w.Code)
+	})
+
+	t.Run("path param not found", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/foo/123", nil)
+		w := httptest.NewRecorder()
+		c := NewContext[any](w, r, readOptions{})
+
+		_, err := c.PathParamIntErr("id")
+		require.ErrorAs(t, err, &PathParamNotFoundError{})
+		require.Empty(t, c.PathParams())
 	})
 }
 
@@ -100,6 +173,110 @@ func TestContext_QueryParam(t *testing.T) {
 	})
 }
 
+type testParams struct {
+	Search string    `query:"search" description:"Search in names"`
+	Limit  int       `query:"limit" default:"10" validate:"max=100"`
+	Tags   []string  `query:"tags" default:"a,b"`
+	After  time.Time `query:"after"`
+	Strict bool      `query:"strict" validate:"required"`
+	Ignore string    `query:"-"`
+}
+
+func TestContextWithParams(t *testing.T) {
+	s := NewServer()
+	Get(s, "/params", func(c *ContextWithParams[testParams]) (testParams, error) {
+		return c.Params()
+	})
+
+	t.Run("can decode params", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/params?search=pie&limit=3&tags=x&tags=y&after=2024-01-02&strict=true&Ignore=no&unknown=1", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 200, w.Code)
+		var params testParams
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &params))
+		require.Equal(t, testParams{
+			Search: "pie",
+			Limit:  3,
+			Tags:   []string{"x", "y"},
+			After:  time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			Strict: true,
+		}, params)
+	})
+
+	t.Run("uses default values", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/params?strict=true", nil)
+		w := httptest.NewRecorder()
+
+		c := &ContextWithParams[testParams]{ContextNoBody: ContextNoBody{request: r, response: w}}
+		params, err := c.Params()
+		require.NoError(t, err)
+		require.Equal(t, 10, params.Limit)
+		require.Equal(t, []string{"a", "b"}, params.Tags)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/params?limit=abc&strict=true", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 400, w.Code)
+		require.Contains(t, w.Body.String(), "cannot decode query parameters")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/params?limit=1000&strict=true", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 400, w.Code)
+		require.Contains(t, w.Body.String(), "Limit should be max=100")
+	})
+}
+
+func TestContextWithParams_PathParams(t *testing.T) {
+	type recipeParams struct {
+		AuthorID uuid.UUID `path:"author_id"`
+		ID       int       `path:"id"`
+		Search   string    `query:"search"`
+	}
+
+	s := NewServer()
+	Get(s, "/authors/{author_id}/recipes/{id}", func(c *ContextWithParams[recipeParams]) (recipeParams, error) {
+		return c.Params()
+	})
+
+	t.Run("can decode path params", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/authors/d5c3c1d4-2a4b-4b8f-9d6e-3c2b1a0f9e8d/recipes/42?search=pie&ID=7", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 200, w.Code)
+		var params recipeParams
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &params))
+		require.Equal(t, recipeParams{
+			AuthorID: uuid.MustParse("d5c3c1d4-2a4b-4b8f-9d6e-3c2b1a0f9e8d"),
+			ID:       42,
+			Search:   "pie",
+		}, params)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/authors/d5c3c1d4-2a4b-4b8f-9d6e-3c2b1a0f9e8d/recipes/abc", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 400, w.Code)
+		require.Contains(t, w.Body.String(), "path param id=abc is not of type int")
+	})
+}
+
 func TestContext_QueryParams(t *testing.T) {
 	r := httptest.NewRequest("GET", "http://example.com/foo/123?id=456&other=hello", nil)
 	w := httptest.NewRecorder()
@@ -208,6 +385,11 @@ func TestContext_Body(t *testing.T) {
 		require.Error(t, err)
 		require.Equal(t, body.Name, "VeryLongName")
 		require.Equal(t, body.Age, 12)
+
+		var badRequest BadRequestError
+		require.ErrorAs(t, err, &badRequest)
+		require.Equal(t, http.StatusBadRequest, ErrorHandler(err).(HTTPError).StatusCode)
+		require.Len(t, badRequest.Info()["validation"], 2)
 	})
 
 	t.Run("can transform JSON body with custom method", func(t *testing.T) {
@@ -265,6 +447,69 @@ func TestContext_Body(t *testing.T) {
 		_, err := c.Body()
 		require.NoError(t, err)
 	})
+
+	t.Run("can read JSON body with media type parameters", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(`{"name":"John","age":30}`))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		c := NewContext[testStruct](httptest.NewRecorder(), r, readOptions{})
+
+		body, err := c.Body()
+		require.NoError(t, err)
+		require.Equal(t, "John", body.Name)
+	})
+
+	t.Run("cannot read unsupported media type", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(`name: John`))
+		r.Header.Set("Content-Type", "application/x-yaml")
+
+		c := NewContext[testStruct](httptest.NewRecorder(), r, readOptions{})
+
+		_, err := c.Body()
+		var errStatus ErrorWithStatus
+		require.ErrorAs(t, err, &errStatus)
+		require.Equal(t, http.StatusUnsupportedMediaType, errStatus.Status())
+	})
+
+	t.Run("cannot read text body into a struct", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "http://example.com/foo", strings.NewReader(`Hello World`))
+		r.Header.Set("Content-Type", "text/plain")
+
+		c := NewContext[testStruct](httptest.NewRecorder(), r, readOptions{})
+
+		_, err := c.Body()
+		var errStatus ErrorWithStatus
+		require.ErrorAs(t, err, &errStatus)
+		require.Equal(t, http.StatusUnsupportedMediaType, errStatus.Status())
+	})
+
+	t.Run("can read body with a custom deserializer", func(t *testing.T) {
+		s := NewServer(
+			WithMediaTypeDeserializer("application/xml", func(r *http.Request, body any, _ DeserializeOptions) error {
+				return xml.NewDecoder(r.Body).Decode(body)
+			}),
+		)
+		Post(s, "/xml", func(c *ContextWithBody[testStruct]) (testStruct, error) {
+			return c.Body()
+		})
+
+		r := httptest.NewRequest("POST", "/xml", strings.NewReader(`<testStruct><Name>John</Name><Age>30</Age></testStruct>`))
+		r.Header.Set("Content-Type", "application/xml")
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, crlf(`{"name":"John","age":30}`), w.Body.String())
+
+		r = httptest.NewRequest("POST", "/xml", strings.NewReader(`name: John`))
+		r.Header.Set("Content-Type", "application/x-yaml")
+		w = httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
 }
 
 func FuzzContext_Body(f *testing.F) {
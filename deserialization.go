@@ -3,12 +3,16 @@ package fuego
 import (
 	"context"
 	"database/sql"
+	"encoding"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/schema"
 )
@@ -40,13 +44,9 @@ func readJSON[B any](context context.Context, input io.Reader, options readOptio
 	var body B
 
 	// Deserialize the request body.
-	dec := json.NewDecoder(input)
-	if options.DisallowUnknownFields {
-		dec.DisallowUnknownFields()
-	}
-	err := dec.Decode(&body)
+	err := decodeJSON(input, &body, options.DisallowUnknownFields)
 	if err != nil {
-		return body, BadRequestError{Message: "cannot decode request body: " + err.Error()}
+		return body, err
 	}
 	slog.Debug("Decoded body", "body", body)
 
@@ -63,6 +63,18 @@ func readJSON[B any](context context.Context, input io.Reader, options readOptio
 	return body, nil
 }
 
+func decodeJSON(input io.Reader, body any, disallowUnknownFields bool) error {
+	dec := json.NewDecoder(input)
+	if disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(body)
+	if err != nil {
+		return BadRequestError{Message: "cannot decode request body: " + err.Error(), Err: err}
+	}
+	return nil
+}
+
 // ReadString reads the request body as string.
 // Can be used independantly from Fuego framework.
 // Customisable by modifying ReadOptions.
@@ -100,6 +112,17 @@ func convertSQLNullBool(value string) reflect.Value {
 	return reflect.ValueOf(v)
 }
 
+// convertTime accepts RFC 3339 date-times (2006-01-02T15:04:05Z07:00) and full dates (2006-01-02).
+func convertTime(value string) reflect.Value {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return reflect.ValueOf(t)
+		}
+	}
+	return reflect.Value{}
+}
+
 func newDecoder() *schema.Decoder {
 	decoder := schema.NewDecoder()
 	decoder.RegisterConverter(sql.NullString{}, convertSQLNullString)
@@ -107,7 +130,225 @@ func newDecoder() *schema.Decoder {
 	return decoder
 }
 
-var decoder = newDecoder()
+// The form decoders, ignoring the unknown fields or not. They are not reconfigured per request, as they are shared.
+var (
+	lenientDecoder = newFormDecoder(true)
+	strictDecoder  = newFormDecoder(false)
+)
+
+func newFormDecoder(ignoreUnknownKeys bool) *schema.Decoder {
+	decoder := newDecoder()
+	decoder.IgnoreUnknownKeys(ignoreUnknownKeys)
+	return decoder
+}
+
+// formDecoder returns the form decoder matching the options.
+func formDecoder(options DeserializeOptions) *schema.Decoder {
+	if options.DisallowUnknownFields {
+		return strictDecoder
+	}
+	return lenientDecoder
+}
+
+func newQueryDecoder() *schema.Decoder {
+	decoder := newDecoder()
+	decoder.RegisterConverter(time.Time{}, convertTime)
+	decoder.SetAliasTag("query")
+	decoder.IgnoreUnknownKeys(true)
+	return decoder
+}
+
+var queryDecoder = newQueryDecoder()
+
+// fieldAlias returns the name of the form or query parameter bound to the struct field.
+// It is the value of the given tag, or the field name if there is no tag.
+// It returns an empty string if the field must be ignored.
+func fieldAlias(field reflect.StructField, tagName string) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get(tagName), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+// queryParamName returns the name of the query parameter bound to the struct field, from its `query` tag.
+func queryParamName(field reflect.StructField) string {
+	return fieldAlias(field, "query")
+}
+
+// queryFields returns the fields of the struct bound to query parameters.
+// The fields of embedded structs without `query` tag are promoted, like [OffsetPagination] in:
+//
+//	type RecipesParams struct {
+//		fuego.OffsetPagination
+//		Search string `query:"search"`
+//	}
+//
+// Fields with a `path` tag are bound to path parameters instead, see [pathFields].
+func queryFields(t reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup("path"); ok {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag.Get("query") == "" {
+			fields = append(fields, queryFields(field.Type)...)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// pathFields returns the fields of the struct bound to path parameters, from their `path` tag.
+// The fields of embedded structs are promoted.
+func pathFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for _, field := range reflect.VisibleFields(t) {
+		if name := field.Tag.Get("path"); field.IsExported() && name != "" && name != "-" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// readPathParams sets the fields of the struct bound to path parameters, from the path of the request.
+// It returns a [PathParamInvalidTypeError] if a path parameter cannot be converted to the type of its field.
+func readPathParams(r *http.Request, params any) error {
+	v := reflect.ValueOf(params).Elem()
+	for _, field := range pathFields(v.Type()) {
+		name := field.Tag.Get("path")
+		value := r.PathValue(name)
+		if value == "" {
+			continue
+		}
+		fieldValue, err := v.FieldByIndexErr(field.Index)
+		if err != nil {
+			continue // Field of a nil embedded pointer.
+		}
+		err = setParam(fieldValue, value)
+		if err != nil {
+			return PathParamInvalidTypeError{
+				ParamName:    name,
+				ParamValue:   value,
+				ExpectedType: field.Type.String(),
+				Err:          err,
+			}
+		}
+	}
+	return nil
+}
+
+// setParam converts the parameter to the type of the field.
+// Types implementing [encoding.TextUnmarshaler], like [uuid.UUID] or [time.Time], are supported.
+func setParam(field reflect.Value, value string) error {
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(value))
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
+
+// ReadQueryParams reads the query parameters of the request into the given struct.
+// Can be used independantly from Fuego framework.
+// See [ContextWithParams] for the supported tags.
+func ReadQueryParams[P any](r *http.Request) (P, error) {
+	return readQueryParams[P](r, ReadOptions)
+}
+
+// readQueryParams reads the query parameters of the request into the given struct,
+// and the path parameters into the fields with a `path` tag.
+// Fields with a `default` tag are set to this value if the parameter is not in the query.
+// Default values of slices are comma separated.
+func readQueryParams[P any](r *http.Request, options readOptions) (P, error) {
+	var params P
+
+	query := r.URL.Query()
+	paramsType := reflect.TypeOf(params)
+	if paramsType == nil || paramsType.Kind() != reflect.Struct {
+		return params, fmt.Errorf("query params must be decoded into a struct, got %T", params)
+	}
+
+	for _, field := range queryFields(paramsType) {
+		name := queryParamName(field)
+		defaultValue, ok := field.Tag.Lookup("default")
+		if name == "" || !ok || query.Has(name) {
+			continue
+		}
+
+		if field.Type.Kind() == reflect.Slice {
+			query[name] = strings.Split(defaultValue, ",")
+		} else {
+			query.Set(name, defaultValue)
+		}
+	}
+
+	err := queryDecoder.Decode(&params, query)
+	if err != nil {
+		return params, BadRequestError{
+			Message: "cannot decode query parameters: " + err.Error(),
+			Err:     err,
+			MoreInfo: map[string]any{
+				"query": r.URL.RawQuery,
+				"help":  "check that the query parameters have the correct type",
+			},
+		}
+	}
+	err = readPathParams(r, &params)
+	if err != nil {
+		return params, err
+	}
+	slog.Debug("Decoded query params", "params", params)
+
+	params, err = transform(r.Context(), params)
+	if err != nil {
+		return params, err
+	}
+
+	err = validate(params)
+	if err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
 
 // ReadURLEncoded reads the request body as HTML Form.
 func ReadURLEncoded[B any](r *http.Request) (B, error) {
@@ -120,23 +361,9 @@ func ReadURLEncoded[B any](r *http.Request) (B, error) {
 func readURLEncoded[B any](r *http.Request, options readOptions) (B, error) {
 	var body B
 
-	err := r.ParseForm()
+	err := DeserializeURLEncoded(r, &body, DeserializeOptions{DisallowUnknownFields: options.DisallowUnknownFields})
 	if err != nil {
-		return body, fmt.Errorf("cannot parse form: %w", err)
-	}
-
-	decoder.IgnoreUnknownKeys(!options.DisallowUnknownFields)
-
-	err = decoder.Decode(&body, r.PostForm)
-	if err != nil {
-		return body, BadRequestError{
-			Message: "cannot decode x-www-form-urlencoded request body: " + err.Error(),
-			Err:     err,
-			MoreInfo: map[string]any{
-				"form": r.PostForm,
-				"help": "check that the form is valid, and that the content-type is correct",
-			},
-		}
+		return body, err
 	}
 	slog.Debug("Decoded body", "body", body)
 
@@ -160,6 +387,77 @@ func readURLEncoded[B any](r *http.Request, options readOptions) (B, error) {
 	return body, nil
 }
 
+// DeserializeOptions are the options given to a [Deserializer].
+type DeserializeOptions struct {
+	DisallowUnknownFields bool // See [WithDisallowUnknownFields].
+}
+
+// Deserializer decodes the request body into body, a pointer to the expected type.
+// Fuego then transforms (see [InTransformer]) and validates the decoded body.
+// Deserializers are registered by media type with [WithMediaTypeDeserializer].
+type Deserializer func(r *http.Request, body any, options DeserializeOptions) error
+
+// defaultDeserializers are used when the context has not been created by a [Server], for example with [NewContext].
+var defaultDeserializers = map[string]Deserializer{
+	"application/json":                  DeserializeJSON,
+	"application/x-www-form-urlencoded": DeserializeURLEncoded,
+	"multipart/form-data":               DeserializeMultipart,
+	"text/plain":                        DeserializeText,
+}
+
+// DeserializeJSON decodes a JSON request body (application/json).
+func DeserializeJSON(r *http.Request, body any, options DeserializeOptions) error {
+	return decodeJSON(r.Body, body, options.DisallowUnknownFields)
+}
+
+// DeserializeURLEncoded decodes an HTML Form request body (application/x-www-form-urlencoded).
+func DeserializeURLEncoded(r *http.Request, body any, options DeserializeOptions) error {
+	err := r.ParseForm()
+	if err != nil {
+		return BadRequestError{Message: "cannot parse form: " + err.Error(), Err: err}
+	}
+
+	err = formDecoder(options).Decode(body, r.PostForm)
+	if err != nil {
+		return BadRequestError{
+			Message: "cannot decode x-www-form-urlencoded request body: " + err.Error(),
+			Err:     err,
+			MoreInfo: map[string]any{
+				"form": r.PostForm,
+				"help": "check that the form is valid, and that the content-type is correct",
+			},
+		}
+	}
+
+	return nil
+}
+
+// DeserializeText reads a plain text request body (text/plain).
+// The body must be a string, or a type based on string.
+func DeserializeText(r *http.Request, body any, _ DeserializeOptions) error {
+	value := reflect.ValueOf(body).Elem()
+	isAny := value.Kind() == reflect.Interface && value.NumMethod() == 0
+	if value.Kind() != reflect.String && !isAny {
+		return HTTPError{
+			StatusCode: http.StatusUnsupportedMediaType,
+			Message:    "a text/plain request body cannot be read as " + value.Type().String(),
+		}
+	}
+
+	readBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		return BadRequestError{Message: "cannot read request body: " + err.Error(), Err: err}
+	}
+
+	if isAny {
+		value.Set(reflect.ValueOf(string(readBody)))
+	} else {
+		value.SetString(string(readBody))
+	}
+
+	return nil
+}
+
 // transforms the input if possible.
 func transform[B any](ctx context.Context, body B) (B, error) {
 	if inTransformerBody, ok := any(&body).(InTransformer); ok {
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -174,6 +175,25 @@ func TestReadURLEncoded(t *testing.T) {
 		require.ErrorAs(t, err, &BadRequestError{}, "Expected a BadRequestError")
 		require.Equal(t, BodyTestWithInTransformerError{"a", 9}, res)
 	})
+	t.Run("unknown fields, concurrently", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(strict bool) {
+				defer wg.Done()
+				r := httptest.NewRequest("POST", "/", strings.NewReader(`A=a&Unknown=1`))
+				r.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+				var body BodyTest
+				err := DeserializeURLEncoded(r, &body, DeserializeOptions{DisallowUnknownFields: strict})
+				if strict {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+			}(i%2 == 0)
+		}
+		wg.Wait()
+	})
 }
 
 func TestConvertSQLNullString(t *testing.T) {
@@ -2,7 +2,6 @@ package fuego
 
 import (
 	"errors"
-	"log/slog"
 	"net/http"
 )
 
@@ -22,10 +21,10 @@ type ErrorWithInfo interface {
 
 // HTTPError is the error response used by the serialization part of the framework.
 type HTTPError struct {
-	Err        error          `json:",omitempty"`                          // backend developer readable error message
-	Message    string         `json:"error" xml:"Error"`                   // human readable error message
-	StatusCode int            `json:"-" xml:"-"`                           // http status code
-	MoreInfo   map[string]any `json:"info,omitempty" xml:"Info,omitempty"` // additional info
+	Err        error          `json:",omitempty" xml:"-"`     // backend developer readable error message
+	Message    string         `json:"error" xml:"Error"`      // human readable error message
+	StatusCode int            `json:"-" xml:"-"`              // http status code
+	MoreInfo   map[string]any `json:"info,omitempty" xml:"-"` // additional info. Not sent in XML, as maps cannot be serialized in XML: use [ProblemDetails] instead.
 }
 
 var (
@@ -72,9 +71,114 @@ func (e BadRequestError) Status() int {
 	return http.StatusBadRequest
 }
 
+func (e BadRequestError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is an error sent with the status code given by its type parameter.
+// Use it through its aliases, like [NotFoundError] or [ConflictError]:
+//
+//	return Recipe{}, fuego.NotFoundError{Message: "recipe not found", Err: err}
+type StatusError[S status] struct {
+	Err      error          // developer readable error message
+	Message  string         `json:"error" xml:"Error"`                   // human readable error message. Defaults to the status text.
+	MoreInfo map[string]any `json:"info,omitempty" xml:"Info,omitempty"` // additional info
+}
+
+var (
+	_ ErrorWithInfo   = StatusError[notFound]{}
+	_ ErrorWithStatus = StatusError[notFound]{}
+)
+
+func (e StatusError[S]) Error() string {
+	return messageOrStatusText(e.Message, e.Status())
+}
+
+func (e StatusError[S]) Info() map[string]any {
+	return e.MoreInfo
+}
+
+func (e StatusError[S]) Status() int {
+	var s S
+	return s.code()
+}
+
+func (e StatusError[S]) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target has the same type and message, so that sentinel errors
+// like [ErrUnauthorized] can be compared with errors.Is.
+func (e StatusError[S]) Is(target error) bool {
+	t, ok := target.(StatusError[S])
+	return ok && t.Message == e.Message
+}
+
+// status is the type parameter of [StatusError], giving its status code.
+type status interface {
+	code() int
+}
+
+type (
+	notFound            struct{}
+	unauthorized        struct{}
+	forbidden           struct{}
+	conflict            struct{}
+	unprocessableEntity struct{}
+	tooManyRequests     struct{}
+	serviceUnavailable  struct{}
+)
+
+func (notFound) code() int            { return http.StatusNotFound }
+func (unauthorized) code() int        { return http.StatusUnauthorized }
+func (forbidden) code() int           { return http.StatusForbidden }
+func (conflict) code() int            { return http.StatusConflict }
+func (unprocessableEntity) code() int { return http.StatusUnprocessableEntity }
+func (tooManyRequests) code() int     { return http.StatusTooManyRequests }
+func (serviceUnavailable) code() int  { return http.StatusServiceUnavailable }
+
+type (
+	// NotFoundError is an error used to return a 404 status code.
+	// The requested resource does not exist.
+	NotFoundError = StatusError[notFound]
+
+	// UnauthorizedError is an error used to return a 401 status code.
+	// The request lacks valid authentication credentials.
+	UnauthorizedError = StatusError[unauthorized]
+
+	// ForbiddenError is an error used to return a 403 status code.
+	// The client is authenticated, but not allowed to access the resource.
+	ForbiddenError = StatusError[forbidden]
+
+	// ConflictError is an error used to return a 409 status code.
+	// The request conflicts with the current state of the resource, for example a duplicate.
+	ConflictError = StatusError[conflict]
+
+	// UnprocessableEntityError is an error used to return a 422 status code.
+	// The request is well-formed, but cannot be processed, for example because of a business rule.
+	UnprocessableEntityError = StatusError[unprocessableEntity]
+
+	// TooManyRequestsError is an error used to return a 429 status code.
+	// The client sent too many requests in a given amount of time.
+	TooManyRequestsError = StatusError[tooManyRequests]
+
+	// ServiceUnavailableError is an error used to return a 503 status code.
+	// The server cannot handle the request, for example because of maintenance or overload.
+	ServiceUnavailableError = StatusError[serviceUnavailable]
+)
+
+// messageOrStatusText returns the message, or the status text if the message is empty.
+func messageOrStatusText(message string, status int) string {
+	if message == "" {
+		return http.StatusText(status)
+	}
+	return message
+}
+
 // ErrorHandler is the default error handler used by the framework.
 // It transforms any error into the unified error type [HTTPError],
 // Using the [ErrorWithStatus] and [ErrorWithInfo] interfaces.
+// It does not log the error: the server logs the errors it handles, with the request context.
 func ErrorHandler(err error) error {
 	errResponse := HTTPError{
 		Message: err.Error(),
@@ -91,7 +195,5 @@ func ErrorHandler(err error) error {
 		errResponse.MoreInfo = errorInfo.Info()
 	}
 
-	slog.Error("Error : "+errResponse.Message, "status:", errResponse.StatusCode, "info:", errResponse.MoreInfo)
-
 	return errResponse
 }
@@ -2,7 +2,9 @@ package fuego
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -52,3 +54,52 @@ func TestErrorHandler(t *testing.T) {
 		require.NotNil(t, errResponse.(HTTPError).Info())
 	})
 }
+
+func TestTypedErrors(t *testing.T) {
+	testCases := []struct {
+		err    error
+		status int
+	}{
+		{err: NotFoundError{}, status: http.StatusNotFound},
+		{err: UnauthorizedError{}, status: http.StatusUnauthorized},
+		{err: ForbiddenError{}, status: http.StatusForbidden},
+		{err: ConflictError{}, status: http.StatusConflict},
+		{err: UnprocessableEntityError{}, status: http.StatusUnprocessableEntity},
+		{err: TooManyRequestsError{}, status: http.StatusTooManyRequests},
+		{err: ServiceUnavailableError{}, status: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(http.StatusText(tc.status), func(t *testing.T) {
+			errResponse := ErrorHandler(tc.err)
+			require.Equal(t, tc.status, errResponse.(HTTPError).Status())
+			require.Equal(t, http.StatusText(tc.status), errResponse.Error(), "defaults to the status text")
+		})
+	}
+
+	t.Run("with message, info and wrapped error", func(t *testing.T) {
+		baseErr := errors.New("duplicate key")
+		err := ConflictError{Err: baseErr, Message: "recipe already exists", MoreInfo: map[string]any{"name": "pizza"}}
+
+		errResponse := ErrorHandler(err)
+		require.Equal(t, "recipe already exists", errResponse.Error())
+		require.Equal(t, map[string]any{"name": "pizza"}, errResponse.(HTTPError).Info())
+		require.ErrorIs(t, err, baseErr)
+	})
+
+	t.Run("sentinel errors keep their status", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendJSONError(w, fmt.Errorf("login: %w", ErrUnauthorized))
+
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.JSONEq(t, `{"error":"login: unauthorized"}`, w.Body.String())
+		require.ErrorIs(t, fmt.Errorf("login: %w", ErrUnauthorized), ErrUnauthorized)
+		require.NotErrorIs(t, ErrForbidden, ErrUnauthorized)
+		require.Equal(t, http.StatusUnauthorized, ErrorHandler(ErrInvalidTokenType).(HTTPError).Status())
+
+		var unauthorized UnauthorizedError
+		require.ErrorAs(t, fmt.Errorf("login: %w", ErrTokenNotFound), &unauthorized)
+		require.Equal(t, "token not found", unauthorized.Message)
+		require.NotErrorIs(t, ErrTokenNotFound, ErrUnauthorized)
+	})
+}
@@ -1,6 +1,6 @@
 module basic
 
-go 1.21.5
+go 1.22
 
 require (
 	github.com/go-chi/chi/v5 v5.0.11
@@ -4,7 +4,7 @@ import (
 	"time"
 
 	"github.com/go-fuego/fuego"
-	"github.com/rs/cors"
+	"github.com/go-fuego/fuego/middleware/cors"
 )
 
 // Ressource is the global struct that holds useful sources of informations available for the controllers.
@@ -21,7 +21,14 @@ type Ressource struct {
 }
 
 func (rs Ressource) MountRoutes(s *fuego.Server) {
-	fuego.Use(s, cors.Default().Handler)
+	// The admin group is created before CORS is enabled on the server, so that its CORS middleware is registered after the auth walls:
+	// the preflight requests are answered before the auth walls, and their errors have the CORS headers.
+	adminRoutes := fuego.Group(s, "/admin")
+	fuego.Use(adminRoutes, fuego.AuthWall("admin", "superadmin"))  // Only admin and superadmin can access the routes in this group
+	fuego.Use(adminRoutes, fuego.AuthWallRegex(`^(super)?admin$`)) // Same as above, but with a regex
+	cors.Use(adminRoutes, cors.Config{})
+
+	cors.Use(s, cors.Config{})
 
 	recipeRessource{
 		RecipeRepository:     rs.RecipesQueries,
@@ -46,10 +53,6 @@ func (rs Ressource) MountRoutes(s *fuego.Server) {
 		return "My name is" + claims.Username, nil
 	})
 
-	adminRoutes := fuego.Group(s, "/admin")
-	fuego.Use(adminRoutes, fuego.AuthWall("admin", "superadmin"))  // Only admin and superadmin can access the routes in this group
-	fuego.Use(adminRoutes, fuego.AuthWallRegex(`^(super)?admin$`)) // Same as above, but with a regex
-
 	fuego.Get(adminRoutes, "/users", placeholderController).
 		WithDescription("Get all users").
 		WithSummary("Get all users").
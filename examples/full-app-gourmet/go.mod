@@ -1,6 +1,6 @@
 module simple-crud
 
-go 1.21.3
+go 1.22
 
 require (
 	github.com/a-h/templ v0.2.513
@@ -11,7 +11,6 @@ require (
 	github.com/google/uuid v1.5.0
 	github.com/joho/godotenv v1.5.1
 	github.com/lmittmann/tint v1.0.3
-	github.com/rs/cors v1.10.1
 	github.com/stretchr/testify v1.8.4
 	golang.org/x/text v0.14.0
 	modernc.org/sqlite v1.28.0
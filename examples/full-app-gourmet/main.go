@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log/slog"
 	"os"
@@ -71,6 +72,9 @@ func main() {
 	}
 
 	app := rs.Setup(fuego.WithPort(*port))
+	app.OnShutdown(func(context.Context) error {
+		return db.Close()
+	})
 
 	// Run the server!
 	err = app.Run()
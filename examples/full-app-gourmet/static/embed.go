@@ -2,14 +2,11 @@ package static
 
 import (
 	"embed"
-	"net/http"
+	"io/fs"
 )
 
 //go:embed *
 var staticFiles embed.FS
 
-// Handler returns a http.Handler that will serve files from
-// the given file system.
-func Handler() http.Handler {
-	return http.FileServer(http.FS(staticFiles))
-}
+// FS contains the static files, served with fuego.Static.
+var FS fs.FS = staticFiles
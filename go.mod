@@ -1,12 +1,14 @@
 module github.com/go-fuego/fuego
 
-go 1.21.3
+go 1.22
 
 require (
+	github.com/andybalholm/brotli v1.2.0
 	github.com/getkin/kin-openapi v0.122.0
 	github.com/go-playground/validator/v10 v10.16.0
 	github.com/golang-jwt/jwt/v5 v5.2.0
 	github.com/gomarkdown/markdown v0.0.0-20231222211730-1d6d20845b47
+	github.com/google/uuid v1.5.0
 	github.com/gorilla/schema v1.2.1
 	github.com/hashicorp/golang-lru/v2 v2.0.7
 	github.com/stretchr/testify v1.8.4
@@ -0,0 +1,193 @@
+package fuego
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+	healthStatusError       = "error"
+
+	defaultHealthCheckTimeout  = 5 * time.Second
+	defaultHealthShutdownDelay = 5 * time.Second // Shutdown delay when the readiness route is enabled, see [WithShutdownDelay].
+)
+
+// HealthCheck is a named check of a dependency of the server (database, downstream API, disk...),
+// run by the readiness route. See [WithHealthChecks].
+// Example:
+//
+//	fuego.HealthCheck{
+//		Name:     "database",
+//		Check:    db.PingContext,
+//		Timeout:  time.Second,
+//		CacheFor: 5 * time.Second,
+//	}
+type HealthCheck struct {
+	Name     string
+	Check    func(ctx context.Context) error // Returns an error if the dependency is unhealthy. The context is canceled after the timeout.
+	Timeout  time.Duration                   // Maximum duration of the check. Defaults to 5 seconds.
+	CacheFor time.Duration                   // Duration during which the last result is reused, to avoid overloading the dependency. Not cached if 0.
+}
+
+// HealthReport is the response of the liveness and readiness routes.
+type HealthReport struct {
+	Status string                       `json:"status"`           // "ok" or "unavailable"
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"` // Results of the checks, by name. Only for the readiness route.
+}
+
+// HealthCheckResult is the result of a [HealthCheck].
+type HealthCheckResult struct {
+	Status    string    `json:"status"` // "ok" or "error"
+	Error     string    `json:"error,omitempty"`
+	Duration  string    `json:"duration"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// HealthConfig is the configuration of the health routes. See [WithHealthChecks].
+type HealthConfig struct {
+	LivenessPath  string // Defaults to /health/live
+	ReadinessPath string // Defaults to /health/ready
+}
+
+var defaultHealthConfig = HealthConfig{
+	LivenessPath:  "/health/live",
+	ReadinessPath: "/health/ready",
+}
+
+// healthChecker runs a [HealthCheck], and caches its result.
+type healthChecker struct {
+	check HealthCheck
+
+	mu       sync.Mutex // Held while checking, so concurrent probes do not run the check several times.
+	result   HealthCheckResult
+	hasValue bool
+}
+
+func newHealthChecker(check HealthCheck) *healthChecker {
+	if check.Timeout <= 0 {
+		check.Timeout = defaultHealthCheckTimeout
+	}
+	return &healthChecker{check: check}
+}
+
+func (h *healthChecker) run(ctx context.Context) HealthCheckResult {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.hasValue && time.Since(h.result.CheckedAt) < h.check.CacheFor {
+		return h.result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := runCheck(ctx, h.check.Check)
+	result := HealthCheckResult{
+		Status:    healthStatusOK,
+		Duration:  time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = healthStatusError
+		result.Error = err.Error()
+		slog.Warn("Health check failed", "check", h.check.Name, "error", err)
+	}
+
+	h.result = result
+	h.hasValue = true
+	return result
+}
+
+// runCheck runs the check, and returns when the context is done even if the check does not respect it.
+func runCheck(ctx context.Context, check func(ctx context.Context) error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// health holds the health checks of the server.
+type health struct {
+	config   HealthConfig
+	checkers []*healthChecker
+}
+
+// registerHealthRoutes registers the liveness and readiness routes.
+func (s *Server) registerHealthRoutes() {
+	GetStd(s, s.health.config.LivenessPath, s.livenessHandler).
+		SetTags("Health").
+		WithSummary("Liveness").
+		WithDescription("Reports that the server is running. Does not run the health checks.").
+		AddResponse(http.StatusOK, "The server is running", HealthReport{})
+
+	GetStd(s, s.health.config.ReadinessPath, s.readinessHandler).
+		SetTags("Health").
+		WithSummary("Readiness").
+		WithDescription("Runs the health checks. Unavailable if a check fails, or if the server is shutting down.").
+		AddResponse(http.StatusOK, "The server is ready to receive requests", HealthReport{}).
+		AddResponse(http.StatusServiceUnavailable, "The server is not ready to receive requests", HealthReport{})
+}
+
+func (s *Server) livenessHandler(w http.ResponseWriter, r *http.Request) {
+	sendHealthReport(w, HealthReport{Status: healthStatusOK})
+}
+
+func (s *Server) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := HealthReport{
+		Status: healthStatusOK,
+		Checks: make(map[string]HealthCheckResult, len(s.health.checkers)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, checker := range s.health.checkers {
+		wg.Add(1)
+		go func(checker *healthChecker) {
+			defer wg.Done()
+			result := checker.run(r.Context())
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[checker.check.Name] = result
+			if result.Status != healthStatusOK {
+				report.Status = healthStatusUnavailable
+			}
+		}(checker)
+	}
+	wg.Wait()
+
+	// During the drain, the load balancer must stop sending new requests.
+	if s.ShuttingDown() {
+		report.Status = healthStatusUnavailable
+	}
+
+	sendHealthReport(w, report)
+}
+
+func sendHealthReport(w http.ResponseWriter, report HealthReport) {
+	status := http.StatusOK
+	if report.Status != healthStatusOK {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(report)
+	if err != nil {
+		slog.Error("Cannot serialize health report", "error", err)
+	}
+}
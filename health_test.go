@@ -0,0 +1,141 @@
+package fuego
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func getHealthReport(t *testing.T, s *Server, path string) (int, HealthReport) {
+	t.Helper()
+	r := httptest.NewRequest("GET", path, nil)
+	w := httptest.NewRecorder()
+
+	s.Mux.ServeHTTP(w, r)
+
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	return w.Code, report
+}
+
+func TestHealthChecks(t *testing.T) {
+	var failing atomic.Bool
+	var calls atomic.Int32
+	s := NewServer(
+		WithHealthChecks(
+			HealthCheck{Name: "database", Check: func(context.Context) error {
+				if failing.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			}},
+			HealthCheck{Name: "cached", CacheFor: time.Hour, Check: func(context.Context) error {
+				calls.Add(1)
+				return nil
+			}},
+		),
+	)
+
+	t.Run("liveness", func(t *testing.T) {
+		code, report := getHealthReport(t, s, "/health/live")
+		require.Equal(t, 200, code)
+		require.Equal(t, "ok", report.Status)
+		require.Empty(t, report.Checks)
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		code, report := getHealthReport(t, s, "/health/ready")
+		require.Equal(t, 200, code)
+		require.Equal(t, "ok", report.Status)
+		require.Equal(t, "ok", report.Checks["database"].Status)
+		require.Equal(t, "ok", report.Checks["cached"].Status)
+	})
+
+	t.Run("a failing check makes the server unavailable", func(t *testing.T) {
+		failing.Store(true)
+		defer failing.Store(false)
+
+		code, report := getHealthReport(t, s, "/health/ready")
+		require.Equal(t, 503, code)
+		require.Equal(t, "unavailable", report.Status)
+		require.Equal(t, "error", report.Checks["database"].Status)
+		require.Equal(t, "connection refused", report.Checks["database"].Error)
+		require.Equal(t, "ok", report.Checks["cached"].Status)
+	})
+
+	t.Run("results are cached", func(t *testing.T) {
+		require.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("not ready while shutting down", func(t *testing.T) {
+		s.lifecycle.shuttingDown.Store(true)
+		defer s.lifecycle.shuttingDown.Store(false)
+
+		code, report := getHealthReport(t, s, "/health/ready")
+		require.Equal(t, 503, code)
+		require.Equal(t, "unavailable", report.Status)
+
+		code, _ = getHealthReport(t, s, "/health/live")
+		require.Equal(t, 200, code, "still alive")
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		ready := s.OpenApiSpec.Paths.Find("/health/ready").Get
+		require.NotNil(t, ready.Responses.Value("503"))
+		require.Equal(t, []string{"Health"}, ready.Tags)
+	})
+}
+
+func TestHealthCheckTimeout(t *testing.T) {
+	s := NewServer(
+		WithHealthConfig(HealthConfig{ReadinessPath: "/readyz"}),
+		WithHealthChecks(HealthCheck{Name: "slow", Timeout: 10 * time.Millisecond, Check: func(context.Context) error {
+			time.Sleep(time.Second) // Does not respect the context.
+			return nil
+		}}),
+	)
+
+	start := time.Now()
+	code, report := getHealthReport(t, s, "/readyz")
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, 503, code)
+	require.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+
+	code, _ = getHealthReport(t, s, "/health/live")
+	require.Equal(t, 200, code, "default liveness path")
+}
+
+func TestShutdownDelay(t *testing.T) {
+	t.Run("defaults with the health checks", func(t *testing.T) {
+		require.Equal(t, 5*time.Second, NewServer(WithHealthChecks()).shutdownDelay)
+		require.Equal(t, time.Duration(0), NewServer(WithHealthChecks(), WithShutdownDelay(0)).shutdownDelay)
+		require.Equal(t, time.Duration(0), NewServer().shutdownDelay)
+	})
+
+	s := NewServer(
+		WithoutLogger(),
+		WithHealthChecks(),
+		WithShutdownDelay(100*time.Millisecond),
+	)
+
+	shutdownDone := make(chan error, 1)
+	go func() { shutdownDone <- s.Shutdown(context.Background()) }()
+
+	require.Eventually(t, func() bool {
+		code, _ := getHealthReport(t, s, "/health/ready")
+		return code == 503
+	}, 50*time.Millisecond, time.Millisecond, "not ready during the delay")
+
+	select {
+	case <-shutdownDone:
+		t.Fatal("shutdown should wait for the delay")
+	default:
+	}
+	require.NoError(t, <-shutdownDone)
+}
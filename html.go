@@ -50,9 +50,9 @@ type HTML string
 // H is a shortcut for map[string]any
 type H map[string]any
 
-// loadTemplates
+// loadTemplates parses the templates, with the "asset" function returning the fingerprinted URLs of [Static] files.
 func (s *Server) loadTemplates(patterns ...string) error {
-	tmpl, err := template.ParseFS(s.fs, patterns...)
+	tmpl, err := template.New("").Funcs(s.assets.templateFuncs()).ParseFS(s.fs, patterns...)
 	if err != nil {
 		return fmt.Errorf("failed to parse templates: %w", err)
 	}
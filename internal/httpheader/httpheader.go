@@ -0,0 +1,70 @@
+// Package httpheader reads and updates the HTTP headers handled by several parts of Fuego.
+package httpheader
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AddVary adds the headers to the Vary header, unless already present or if the response varies on everything ("*").
+func AddVary(header http.Header, names ...string) {
+	existing := strings.Join(header.Values("Vary"), ",")
+	if containsToken(existing, "*") {
+		return
+	}
+	for _, name := range names {
+		if !containsToken(existing, name) {
+			header.Add("Vary", name)
+		}
+	}
+}
+
+// NegotiateEncoding returns the offered encoding with the highest weight in the Accept-Encoding header,
+// or "" if none is acceptable. See https://www.rfc-editor.org/rfc/rfc9110#section-12.5.3
+func NegotiateEncoding(acceptEncoding string, offered []string) string {
+	weights := map[string]float64{}
+	wildcard := -1.0
+	for _, accepted := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(accepted, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			weight = parsed
+		}
+		if name == "*" {
+			wildcard = weight
+		} else {
+			weights[name] = weight
+		}
+	}
+
+	best, bestWeight := "", 0.0
+	for _, encoding := range offered {
+		weight, ok := weights[encoding]
+		if !ok {
+			weight = wildcard
+		}
+		if weight > bestWeight {
+			best, bestWeight = encoding, weight
+		}
+	}
+	return best
+}
+
+// containsToken reports whether the comma-separated list contains the token, case-insensitively.
+func containsToken(list, token string) bool {
+	for _, t := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(t), token) {
+			return true
+		}
+	}
+	return false
+}
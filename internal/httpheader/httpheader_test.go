@@ -0,0 +1,34 @@
+package httpheader
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddVary(t *testing.T) {
+	t.Run("adds the missing headers", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Vary", "Accept, origin")
+		AddVary(header, "Origin", "Accept-Encoding")
+		require.Equal(t, []string{"Accept, origin", "Accept-Encoding"}, header.Values("Vary"))
+	})
+
+	t.Run("varies on everything", func(t *testing.T) {
+		header := http.Header{}
+		header.Set("Vary", "*")
+		AddVary(header, "Accept-Encoding")
+		require.Equal(t, []string{"*"}, header.Values("Vary"))
+	})
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	offered := []string{"br", "gzip"}
+	require.Equal(t, "gzip", NegotiateEncoding("GZIP", offered))
+	require.Equal(t, "br", NegotiateEncoding("gzip;q=0.8, br", offered))
+	require.Equal(t, "br", NegotiateEncoding("*", offered))
+	require.Equal(t, "gzip", NegotiateEncoding("gzip, br;q=invalid", offered))
+	require.Equal(t, "gzip", NegotiateEncoding("br;q=0, *", offered))
+	require.Empty(t, NegotiateEncoding("deflate", offered))
+}
@@ -0,0 +1,85 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// lifecycle holds the hooks run when the server starts and shuts down.
+// It is a pointer shared by the server and its groups, so hooks can be registered from any group.
+type lifecycle struct {
+	mu         sync.Mutex
+	onStart    []func(ctx context.Context) error
+	onShutdown []func(ctx context.Context) error
+
+	shuttingDown atomic.Bool
+	shutdownOnce sync.Once
+	shutdownErr  error
+}
+
+func newLifecycle() *lifecycle {
+	return &lifecycle{}
+}
+
+// OnStart registers a hook run before the server starts listening, in registration order.
+// If a hook returns an error, the server does not start and [Server.RunContext] returns the error.
+// Example:
+//
+//	s.OnStart(func(ctx context.Context) error {
+//		return db.PingContext(ctx)
+//	})
+func (s *Server) OnStart(hook func(ctx context.Context) error) {
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+	s.lifecycle.onStart = append(s.lifecycle.onStart, hook)
+}
+
+// OnShutdown registers a hook run once the server has stopped accepting requests and drained the in-flight ones,
+// in reverse registration order (like defer). All hooks are run, even if some of them return an error.
+// The context is canceled at the end of the drain timeout, see [WithShutdownTimeout].
+// Example:
+//
+//	s.OnShutdown(func(ctx context.Context) error {
+//		return db.Close()
+//	})
+func (s *Server) OnShutdown(hook func(ctx context.Context) error) {
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+	s.lifecycle.onShutdown = append(s.lifecycle.onShutdown, hook)
+}
+
+// ShuttingDown reports whether the server is shutting down: it should not receive new requests.
+func (s *Server) ShuttingDown() bool {
+	return s.lifecycle.shuttingDown.Load()
+}
+
+func (l *lifecycle) runStartHooks(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := slices.Clone(l.onStart)
+	l.mu.Unlock()
+
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("start hook %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (l *lifecycle) runShutdownHooks(ctx context.Context) error {
+	l.mu.Lock()
+	hooks := slices.Clone(l.onShutdown)
+	l.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
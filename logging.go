@@ -0,0 +1,188 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header used to read and send the request ID. See [WithRequestID].
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+const contextKeyRequestID contextKey = "requestID"
+
+// RequestIDFromContext returns the ID of the request, or an empty string if [WithRequestID] is not used.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKeyRequestID).(string)
+	return id
+}
+
+// validRequestID reports whether the request ID sent by the client can be reused:
+// it must be short, and only contain characters that are safe in logs and headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' || r == ':' || r == '/' || r == '+' || r == '=') {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDMiddleware reuses the request ID sent by the client (ex: by a proxy) or generates one,
+// stores it in the request context and sends it back in the response.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKeyRequestID, id)))
+	})
+}
+
+// requestIDLogHandler adds the request ID to the records logged by the server with a request context,
+// or to all the records of the logger of a request, see [Ctx.Logger].
+type requestIDLogHandler struct {
+	slog.Handler
+	id string // Request ID of the records. If empty, read from the context of each record.
+}
+
+func (h requestIDLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	id := h.id
+	if id == "" {
+		id = RequestIDFromContext(ctx)
+	}
+	if id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h requestIDLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDLogHandler{h.Handler.WithAttrs(attrs), h.id}
+}
+
+func (h requestIDLogHandler) WithGroup(name string) slog.Handler {
+	return requestIDLogHandler{h.Handler.WithGroup(name), h.id}
+}
+
+// newServerLogger returns the logger of the server: the default logger, adding the request ID to the records if [WithRequestID] is used.
+// The default logger itself is left unchanged, and is resolved when logging, so that later calls to slog.SetDefault are followed.
+func newServerLogger(requestID bool) *slog.Logger {
+	if !requestID {
+		return slog.New(defaultLogHandler{})
+	}
+	return slog.New(requestIDLogHandler{Handler: defaultLogHandler{}})
+}
+
+// defaultLogHandler sends the records to the handler of the default logger at the time they are logged.
+type defaultLogHandler struct {
+	with func(slog.Handler) slog.Handler // Applies the attributes and groups added with WithAttrs and WithGroup, if any.
+}
+
+func (h defaultLogHandler) handler() slog.Handler {
+	handler := slog.Default().Handler()
+	if h.with != nil {
+		handler = h.with(handler)
+	}
+	return handler
+}
+
+func (h defaultLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler().Enabled(ctx, level)
+}
+
+func (h defaultLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler().Handle(ctx, record)
+}
+
+func (h defaultLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return defaultLogHandler{with: func(handler slog.Handler) slog.Handler {
+		if h.with != nil {
+			handler = h.with(handler)
+		}
+		return handler.WithAttrs(attrs)
+	}}
+}
+
+func (h defaultLogHandler) WithGroup(name string) slog.Handler {
+	return defaultLogHandler{with: func(handler slog.Handler) slog.Handler {
+		if h.with != nil {
+			handler = h.with(handler)
+		}
+		return handler.WithGroup(name)
+	}}
+}
+
+// logError logs an error transformed by the error handler, with the request context, so with its ID if [WithRequestID] is used.
+func logError(logger *slog.Logger, ctx context.Context, err error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	status := http.StatusInternalServerError
+	var errorStatus ErrorWithStatus
+	if errors.As(err, &errorStatus) {
+		status = errorStatus.Status()
+	}
+	var info map[string]any
+	var errorInfo ErrorWithInfo
+	if errors.As(err, &errorInfo) {
+		info = errorInfo.Info()
+	}
+
+	logger.ErrorContext(ctx, "Error : "+err.Error(), "status:", status, "info:", info)
+}
+
+// requestLogger returns the logger of a request, adding its ID to all the records if [WithRequestID] is used.
+func requestLogger(logger *slog.Logger, ctx context.Context) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+	handler, ok := logger.Handler().(requestIDLogHandler)
+	id := RequestIDFromContext(ctx)
+	if !ok || id == "" {
+		return logger
+	}
+	return slog.New(requestIDLogHandler{Handler: handler.Handler, id: id})
+}
+
+// accessLogMiddleware logs a record for each request with the logger of the server, once it is served.
+// Server errors are logged at the error level, client errors at the warning level.
+// It must be called after the route pattern is stored in the request context.
+func accessLogMiddleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		writer := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(writer, r)
+
+		status := writer.statusCode()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		} else if status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		}
+
+		logger.LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("route", routePattern(r.Context())),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", writer.bytes),
+			slog.Duration("latency", time.Since(start)),
+		)
+	})
+}
@@ -0,0 +1,173 @@
+package fuego
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// captureLogs returns a buffer for the logs of a server created with [WithLogHandler],
+// and restores the default logger, set by [WithLogHandler], at the end of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	previous := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &bytes.Buffer{}
+}
+
+// logRecords returns the records with the given message.
+func logRecords(t *testing.T, logs *bytes.Buffer, msg string) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record["msg"] == msg {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+func TestRequestID(t *testing.T) {
+	newServer := func(t *testing.T) (*Server, *bytes.Buffer) {
+		t.Helper()
+		logs := captureLogs(t)
+		s := NewServer(
+			WithLogHandler(slog.NewJSONHandler(logs, nil)),
+			WithRequestID(),
+		)
+		Get(s, "/recipes", func(c *ContextNoBody) (string, error) {
+			c.Logger().Info("listing recipes")
+			c.Logger().InfoContext(c.Context(), "listed recipes")
+			return c.RequestID(), nil
+		})
+		return s, logs
+	}
+
+	t.Run("generated", func(t *testing.T) {
+		s, _ := newServer(t)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest("GET", "/recipes", nil))
+
+		id := w.Header().Get("X-Request-ID")
+		require.Len(t, id, 36)
+		require.Equal(t, id, w.Body.String())
+	})
+
+	t.Run("from the client", func(t *testing.T) {
+		s, _ := newServer(t)
+		r := httptest.NewRequest("GET", "/recipes", nil)
+		r.Header.Set("X-Request-ID", "abc-123")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, "abc-123", w.Header().Get("X-Request-ID"))
+		require.Equal(t, "abc-123", w.Body.String())
+	})
+
+	t.Run("invalid from the client", func(t *testing.T) {
+		s, _ := newServer(t)
+		for _, id := range []string{"with space", "line\nbreak", strings.Repeat("a", 129)} {
+			r := httptest.NewRequest("GET", "/recipes", nil)
+			r.Header.Set("X-Request-ID", id)
+			w := httptest.NewRecorder()
+			s.Mux.ServeHTTP(w, r)
+
+			require.Len(t, w.Header().Get("X-Request-ID"), 36)
+		}
+	})
+
+	t.Run("added to the controller logs", func(t *testing.T) {
+		s, logs := newServer(t)
+		r := httptest.NewRequest("GET", "/recipes", nil)
+		r.Header.Set("X-Request-ID", "abc-123")
+		s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+
+		records := logRecords(t, logs, "listing recipes")
+		require.Len(t, records, 1)
+		require.Equal(t, "abc-123", records[0]["request_id"])
+		require.Len(t, logRecords(t, logs, "listed recipes"), 1)
+		require.Equal(t, 2, strings.Count(logs.String(), `"request_id"`), "added once per record")
+	})
+
+	t.Run("added to the error logs", func(t *testing.T) {
+		s, logs := newServer(t)
+		Get(s, "/missing", func(c *ContextNoBody) (string, error) {
+			return "", NotFoundError{Message: "recipe not found"}
+		})
+		r := httptest.NewRequest("GET", "/missing", nil)
+		r.Header.Set("X-Request-ID", "abc-123")
+		s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+
+		records := logRecords(t, logs, "Error : recipe not found")
+		require.Len(t, records, 1)
+		require.Equal(t, "abc-123", records[0]["request_id"])
+		require.Equal(t, float64(404), records[0]["status:"])
+	})
+
+	t.Run("default logger set after the server", func(t *testing.T) {
+		logs := captureLogs(t)
+		s := NewServer(WithRequestID())
+		Get(s, "/recipes", func(c *ContextNoBody) (string, error) {
+			c.Logger().Info("listing recipes")
+			return "", nil
+		})
+		slog.SetDefault(slog.New(slog.NewJSONHandler(logs, nil)))
+
+		r := httptest.NewRequest("GET", "/recipes", nil)
+		r.Header.Set("X-Request-ID", "abc-123")
+		s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+
+		records := logRecords(t, logs, "listing recipes")
+		require.Len(t, records, 1)
+		require.Equal(t, "abc-123", records[0]["request_id"])
+	})
+
+	t.Run("default logger unchanged", func(t *testing.T) {
+		captureLogs(t)
+		logger := slog.Default()
+		NewServer(WithRequestID())
+		require.Same(t, logger, slog.Default())
+	})
+}
+
+func TestAccessLog(t *testing.T) {
+	logs := captureLogs(t)
+	s := NewServer(
+		WithLogHandler(slog.NewJSONHandler(logs, nil)),
+		WithRequestID(),
+		WithAccessLog(),
+	)
+	Get(s, "/recipes/{id}", func(c *ContextNoBody) (string, error) {
+		if c.PathParam("id") == "missing" {
+			return "", NotFoundError{}
+		}
+		return "pizza", nil
+	})
+
+	r := httptest.NewRequest("GET", "/recipes/1", nil)
+	r.Header.Set("X-Request-ID", "abc-123")
+	s.Mux.ServeHTTP(httptest.NewRecorder(), r)
+	s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/recipes/missing", nil))
+
+	records := logRecords(t, logs, "request")
+	require.Len(t, records, 2)
+
+	require.Equal(t, "INFO", records[0]["level"])
+	require.Equal(t, "GET", records[0]["method"])
+	require.Equal(t, "/recipes/{id}", records[0]["route"])
+	require.Equal(t, "/recipes/1", records[0]["path"])
+	require.Equal(t, float64(200), records[0]["status"])
+	require.Equal(t, float64(len("pizza")), records[0]["bytes"])
+	require.Contains(t, records[0], "latency")
+	require.Equal(t, "abc-123", records[0]["request_id"])
+
+	require.Equal(t, "WARN", records[1]["level"])
+	require.Equal(t, float64(404), records[1]["status"])
+}
@@ -0,0 +1,241 @@
+package fuego
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MetricsConfig is the configuration of the metrics. See [WithMetrics].
+type MetricsConfig struct {
+	Path    string    // Path of the Prometheus endpoint. Defaults to /metrics
+	Buckets []float64 // Upper bounds of the histogram buckets, in seconds. Defaults to the Prometheus default buckets.
+}
+
+var defaultMetricsConfig = MetricsConfig{
+	Path:    "/metrics",
+	Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+}
+
+const contextKeyTimings contextKey = "timings"
+
+// requestTimings collects the Server-Timing phases of a request, for the metrics.
+type requestTimings struct {
+	timings []Timing
+}
+
+// addTiming adds the timing to the Server-Timing header of the response,
+// and records it for the metrics if they are enabled.
+func addTiming(w http.ResponseWriter, r *http.Request, timing Timing) {
+	w.Header().Add("Server-Timing", timing.String())
+	if recorder, ok := r.Context().Value(contextKeyTimings).(*requestTimings); ok {
+		recorder.timings = append(recorder.timings, timing)
+	}
+}
+
+// histogram is a Prometheus histogram: the count of observations per bucket, their sum and their count.
+type histogram struct {
+	buckets []uint64 // Not cumulative, the last one is +Inf.
+	sum     float64
+	count   uint64
+}
+
+func (h *histogram) observe(upperBounds []float64, value float64) {
+	if h.buckets == nil {
+		h.buckets = make([]uint64, len(upperBounds)+1)
+	}
+	i, _ := slices.BinarySearch(upperBounds, value)
+	h.buckets[i]++
+	h.sum += value
+	h.count++
+}
+
+// metrics holds the histograms of the requests, and their phases.
+// It is a pointer shared by the server and its groups.
+type metrics struct {
+	config MetricsConfig
+
+	mu       sync.Mutex
+	requests map[metricLabels]*histogram
+	phases   map[metricLabels]*histogram
+}
+
+// metricLabels are the labels of a series. The route pattern is used instead of the path,
+// so the number of series does not grow with the path parameters.
+type metricLabels struct {
+	method string
+	route  string
+	status string // Only for the requests.
+	phase  string // Only for the phases.
+}
+
+func newMetrics(config MetricsConfig) *metrics {
+	return &metrics{
+		config:   config,
+		requests: make(map[metricLabels]*histogram),
+		phases:   make(map[metricLabels]*histogram),
+	}
+}
+
+func (m *metrics) observe(series map[metricLabels]*histogram, labels metricLabels, d time.Duration) {
+	h, ok := series[labels]
+	if !ok {
+		h = &histogram{}
+		series[labels] = h
+	}
+	h.observe(m.config.Buckets, d.Seconds())
+}
+
+// statusRecorder records the status and the size of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *statusRecorder) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *statusRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+	return n, err
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush implements [http.Flusher], for the handlers streaming their response.
+func (w *statusRecorder) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements [http.Hijacker], for the handlers taking over the connection, ex: WebSocket upgrades.
+func (w *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil && w.status == 0 {
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+// statusCode returns the status of the response, 200 if the handler did not write it explicitly.
+func (w *statusRecorder) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// middleware measures the requests of a route, and the Server-Timing phases recorded by [addTiming].
+// It must be called after the route pattern is stored in the request context.
+func (m *metrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &requestTimings{}
+		writer := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), contextKeyTimings, recorder)))
+
+		route := routePattern(r.Context())
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.observe(m.requests, metricLabels{method: r.Method, route: route, status: strconv.Itoa(writer.statusCode())}, time.Since(start))
+		for _, timing := range recorder.timings {
+			m.observe(m.phases, metricLabels{method: r.Method, route: route, phase: timing.Name}, timing.Dur)
+		}
+	})
+}
+
+// registerMetricsRoute registers the Prometheus endpoint.
+func (s *Server) registerMetricsRoute() {
+	GetStd(s, s.metrics.config.Path, s.metrics.handler).
+		SetTags("Metrics").
+		WithSummary("Metrics").
+		WithDescription("Durations of the requests and of their phases, by route, in the Prometheus text format.")
+}
+
+func (m *metrics) handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+
+	var sb strings.Builder
+	m.mu.Lock()
+	m.writeHistograms(&sb, "fuego_http_request_duration_seconds", "Duration of the HTTP requests, by route pattern, method and status.", m.requests)
+	m.writeHistograms(&sb, "fuego_http_request_phase_duration_seconds", "Duration of the phases of the HTTP requests (see the Server-Timing header), by route pattern, method and phase.", m.phases)
+	m.mu.Unlock()
+
+	_, err := io.WriteString(w, sb.String())
+	if err != nil {
+		slog.Error("Cannot write metrics", "error", err)
+	}
+}
+
+// writeHistograms writes the series in the Prometheus text exposition format, sorted by labels.
+// See https://prometheus.io/docs/instrumenting/exposition_formats/#text-based-format
+func (m *metrics) writeHistograms(w *strings.Builder, name, help string, series map[metricLabels]*histogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+
+	labels := make([]metricLabels, 0, len(series))
+	for l := range series {
+		labels = append(labels, l)
+	}
+	slices.SortFunc(labels, func(a, b metricLabels) int {
+		return strings.Compare(a.route+" "+a.method+" "+a.status+" "+a.phase, b.route+" "+b.method+" "+b.status+" "+b.phase)
+	})
+
+	for _, l := range labels {
+		h := series[l]
+		formatted := l.format()
+		var cumulative uint64
+		for i, upperBound := range m.config.Buckets {
+			cumulative += h.buckets[i]
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, formatted, formatFloat(upperBound), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, formatted, h.count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, formatted, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, formatted, h.count)
+	}
+}
+
+func (l metricLabels) format() string {
+	s := `method="` + escapeLabelValue(l.method) + `",route="` + escapeLabelValue(l.route) + `"`
+	if l.status != "" {
+		s += `,status="` + l.status + `"`
+	}
+	if l.phase != "" {
+		s += `,phase="` + escapeLabelValue(l.phase) + `"`
+	}
+	return s
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
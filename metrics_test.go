@@ -0,0 +1,91 @@
+package fuego
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func getMetrics(t *testing.T, s *Server) string {
+	t.Helper()
+	r := httptest.NewRequest("GET", s.metrics.config.Path, nil)
+	w := httptest.NewRecorder()
+
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, 200, w.Code)
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	return w.Body.String()
+}
+
+func TestMetrics(t *testing.T) {
+	s := NewServer(
+		WithMetrics(MetricsConfig{Buckets: []float64{1, 0.1}}),
+	)
+	Get(s, "/recipes/{id}", func(c *ContextNoBody) (string, error) {
+		if c.PathParam("id") == "missing" {
+			return "", NotFoundError{}
+		}
+		return "recipe " + c.PathParam("id"), nil
+	})
+	Post(s, "/recipes", func(c *ContextWithBody[MyStruct]) (MyStruct, error) {
+		return c.Body()
+	})
+	group := Group(s, "/api")
+	Get(group, "/error", func(c *ContextNoBody) (any, error) {
+		return nil, errors.New("boom")
+	})
+
+	for _, path := range []string{"/recipes/1", "/recipes/2", "/recipes/missing", "/api/error"} {
+		s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/recipes", strings.NewReader(`{"b":"pizza"}`)))
+
+	metrics := getMetrics(t, s)
+
+	t.Run("requests by route pattern, method and status", func(t *testing.T) {
+		require.Contains(t, metrics, "# TYPE fuego_http_request_duration_seconds histogram\n")
+		require.Contains(t, metrics, `fuego_http_request_duration_seconds_count{method="GET",route="/recipes/{id}",status="200"} 2`+"\n")
+		require.Contains(t, metrics, `fuego_http_request_duration_seconds_count{method="GET",route="/recipes/{id}",status="404"} 1`+"\n")
+		require.Contains(t, metrics, `fuego_http_request_duration_seconds_count{method="GET",route="/api/error",status="500"} 1`+"\n")
+		require.Contains(t, metrics, `fuego_http_request_duration_seconds_count{method="POST",route="/recipes",status="200"} 1`+"\n")
+		require.NotContains(t, metrics, "/recipes/1")
+	})
+
+	t.Run("sorted cumulative buckets", func(t *testing.T) {
+		require.Contains(t, metrics, `fuego_http_request_duration_seconds_bucket{method="GET",route="/recipes/{id}",status="200",le="0.1"} 2`+"\n"+
+			`fuego_http_request_duration_seconds_bucket{method="GET",route="/recipes/{id}",status="200",le="1"} 2`+"\n"+
+			`fuego_http_request_duration_seconds_bucket{method="GET",route="/recipes/{id}",status="200",le="+Inf"} 2`+"\n")
+	})
+
+	t.Run("phases from the Server-Timing header", func(t *testing.T) {
+		require.Contains(t, metrics, "# TYPE fuego_http_request_phase_duration_seconds histogram\n")
+		require.Contains(t, metrics, `fuego_http_request_phase_duration_seconds_count{method="GET",route="/recipes/{id}",phase="fuegoReqInit"} 3`+"\n")
+		for _, phase := range []string{"controller", "write"} {
+			require.Contains(t, metrics, `fuego_http_request_phase_duration_seconds_count{method="GET",route="/recipes/{id}",phase="`+phase+`"} 2`+"\n", "not measured when the controller fails")
+		}
+		for _, phase := range []string{"deserialize", "transformOut", "serialize"} {
+			require.Contains(t, metrics, `fuego_http_request_phase_duration_seconds_count{method="POST",route="/recipes",phase="`+phase+`"} 1`+"\n")
+		}
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		require.NotNil(t, s.OpenApiSpec.Paths.Find("/metrics").Get)
+	})
+}
+
+func TestMetricsLabelEscaping(t *testing.T) {
+	labels := metricLabels{method: "GET", route: `/a"b\c`, phase: "x\ny"}
+	require.Equal(t, `method="GET",route="/a\"b\\c",phase="x\ny"`, labels.format())
+}
+
+func TestAddTiming(t *testing.T) {
+	t.Run("without metrics", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		addTiming(w, httptest.NewRequest("GET", "/", nil), Timing{Name: "test"})
+		require.Equal(t, "test;dur=0", w.Header().Get("Server-Timing"))
+	})
+}
@@ -29,9 +29,8 @@ func New(config Config) func(http.Handler) http.Handler {
 				return
 			}
 
-			err := fuego.HTTPError{
-				Message:    "unauthorized",
-				StatusCode: http.StatusUnauthorized,
+			err := fuego.UnauthorizedError{
+				Message: "unauthorized",
 			}
 
 			w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
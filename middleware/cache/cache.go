@@ -1,25 +1,81 @@
 package cache
 
 import (
-	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 )
 
-type Storage interface {
-	Get(key string) (string, bool)
-	Set(key string, value string)
+type Config struct {
+	Storage              Storage
+	Key                  func(r *http.Request) string // Key returns the cache key for the request. The values of the headers listed in the Vary header of the response are added to it.
+	TTL                  time.Duration                // TTL is the duration responses are cached for, when the handler does not set Cache-Control: max-age. Defaults to 3 seconds.
+	StaleWhileRevalidate time.Duration                // Duration after expiration during which a response is served while it is refreshed in the background, when the handler does not set Cache-Control: stale-while-revalidate.
+	StaleIfError         time.Duration                // Duration after expiration during which a response is served if the handler fails, when the handler does not set Cache-Control: stale-if-error.
 }
 
-type Config struct {
-	Storage Storage
-	Key     func(r *http.Request) string // Key returns the cache key for the request
+// Values of the Cache header.
+const (
+	cacheHit          = "hit"            // fresh response from the cache
+	cacheMiss         = "miss"           // response from the handler
+	cacheCoalesced    = "coalesced"      // response from the handler, for a concurrent request
+	cacheStale        = "stale"          // expired response from the cache, refreshed in the background
+	cacheStaleIfError = "stale-if-error" // expired response from the cache, as the handler failed
+)
+
+// entry is a cached response.
+type entry struct {
+	Status               int         `json:"status"`
+	Header               http.Header `json:"header"`
+	Body                 []byte      `json:"body"`
+	Stored               time.Time   `json:"stored"`
+	Expires              time.Time   `json:"expires"`
+	StaleWhileRevalidate time.Time   `json:"staleWhileRevalidate"` // served while refreshed until then
+	StaleIfError         time.Time   `json:"staleIfError"`         // served if the handler fails until then
 }
 
-// Cache the response of GET requests for a given duration.
-// By default, it will use an in-memory cache with a duration of 3 seconds, with a maximum of 1000 entries.
-// You can provide your own storage implementation by passing a Config struct to the middleware.
+// lastUse returns the time after which the entry cannot be served anymore.
+func (e entry) lastUse() time.Time {
+	last := e.Expires
+	if e.StaleWhileRevalidate.After(last) {
+		last = e.StaleWhileRevalidate
+	}
+	if e.StaleIfError.After(last) {
+		last = e.StaleIfError
+	}
+	return last
+}
+
+// Cache the full response (status, headers and body) of GET requests.
+// By default, it will use an in-memory cache with a maximum of 1000 entries, and responses are cached for 3 seconds.
+// You can provide another storage by passing a Config struct to the middleware,
+// ex: [NewRedisStorage] to share the cache between several servers, or your own implementation.
 // You can also provide your own key function to generate the cache key for a given request.
+//
+// The Cache-Control header set by the handler is respected:
+//   - max-age or s-maxage sets the duration the response is cached for
+//   - no-store, no-cache and private responses are not cached
+//
+// Concurrent requests missing the cache for the same key are coalesced: the handler is called once,
+// and its response is sent to all of them.
+// Expired responses can still be served, as set by the Cache-Control header of the response or the config:
+//   - stale-while-revalidate: the response is sent while it is refreshed in the background
+//   - stale-if-error: the response is sent if the handler fails with a 5xx status
+//
+// The Cache header of the response is "hit", "miss", "coalesced", "stale" or "stale-if-error".
+//
+// Responses vary on the request headers listed in their Vary header, and have an ETag:
+// requests with a matching If-None-Match header are answered with 304 Not Modified.
+// Responses to requests with an Authorization header are only cached if marked as public.
+//
+// A successful POST, PUT, PATCH or DELETE request deletes the cached responses to the same path.
+// Use [Tags] and [Invalidates] to delete the responses of other routes, ex: of /recipes after POST /recipes/new,
+// and [Invalidate] to delete them outside of a request.
+//
 // Headers can be used to invalidate the cache:
 //   - Cache-Control: no-cache will bypass the cache
 //   - Cache-Control: no-store might use the cache but will not store the response in the cache
@@ -29,10 +85,9 @@ func New(config ...Config) func(http.Handler) http.Handler {
 	}
 
 	c := Config{
-		Storage: NewInMemoryCache(3*time.Second, 1000),
-		Key: func(r *http.Request) string {
-			return "httpcache_" + r.URL.Path + "_" + r.Header.Get("Content-Type")
-		},
+		Storage: NewInMemoryCache(time.Hour, 1000),
+		Key:     defaultKey,
+		TTL:     3 * time.Second,
 	}
 
 	if len(config) == 1 {
@@ -43,49 +98,286 @@ func New(config ...Config) func(http.Handler) http.Handler {
 		if config[0].Key != nil {
 			c.Key = config[0].Key
 		}
+
+		if config[0].TTL > 0 {
+			c.TTL = config[0].TTL
+		}
+
+		c.StaleWhileRevalidate = config[0].StaleWhileRevalidate
+		c.StaleIfError = config[0].StaleIfError
 	}
 
+	registerStorage(c.Storage)
+	flights := &flights{calls: make(map[string]*flight)}
+
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isUnsafe(r.Method) {
+				// The responses to the path are in the storage of this middleware only.
+				tags := []string{pathTag(r)}
+				invalidateAfter(w, r, h, tags, func(ctx context.Context) error {
+					return c.Storage.InvalidateTags(ctx, tags...)
+				})
+				return
+			}
 			if r.Method != http.MethodGet {
 				h.ServeHTTP(w, r)
 				return
 			}
 
-			if r.Header.Get("Cache-Control") == "no-cache" {
+			requestDirectives := parseCacheControl(r.Header.Get("Cache-Control"))
+			if _, ok := requestDirectives["no-cache"]; ok {
 				h.ServeHTTP(w, r)
 				return
 			}
 
 			key := c.Key(r)
+			now := time.Now()
+			cached, found := c.get(r, key)
+			switch {
+			case found && now.Before(cached.Expires):
+				writeCached(w, r, cached, cacheHit)
+				return
+			case found && now.Before(cached.StaleWhileRevalidate):
+				c.revalidate(r, h, key, flights)
+				writeCached(w, r, cached, cacheStale)
+				return
+			}
 
-			if val, ok := c.Storage.Get(key); ok {
-				respContentType, ok := c.Storage.Get(key + "_response-content-type")
-				if ok {
-					w.Header().Set("Content-Type", respContentType)
-				}
+			var fallback *entry
+			if found && now.Before(cached.StaleIfError) {
+				fallback = &cached
+			}
 
-				w.WriteHeader(http.StatusOK)
-				w.Header().Set("Cache", "hit")
-				_, _ = w.Write([]byte(val))
+			w.Header().Set("Cache", cacheMiss)
+
+			if _, ok := requestDirectives["no-store"]; ok {
+				h.ServeHTTP(w, r)
 				return
 			}
 
-			if r.Header.Get("Cache-Control") == "no-store" {
-				h.ServeHTTP(w, r)
+			call, leader := flights.join(key, r)
+			if !leader {
+				select {
+				case <-call.done:
+				case <-r.Context().Done():
+					return
+				}
+				if shared, ok := call.sharedWith(r, key); ok {
+					writeCached(w, r, shared, cacheCoalesced)
+					return
+				}
+				c.fill(w, r, h, key, fallback)
 				return
 			}
 
-			buf := &bytes.Buffer{}
-			multiWriter := &MultiHTTPWriter{
-				ResponseWriter: w,
-				cacheWriter:    buf,
+			defer flights.finish(key, call)
+			call.entry, call.ok = c.fill(w, r, h, key, fallback)
+		})
+	}
+}
+
+// fill calls the handler, and stores its response if it can be cached.
+// If the handler fails and a fallback is given, the fallback is sent instead.
+func (c Config) fill(w http.ResponseWriter, r *http.Request, h http.Handler, key string, fallback *entry) (entry, bool) {
+	r = withTags(r, nil)
+	before := w.Header().Clone()
+	recorder := &responseRecorder{
+		ResponseWriter: w,
+		before:         before,
+		ttl: func(status int, header http.Header) (lifetime, bool) {
+			return c.ttl(r, status, header)
+		},
+		holdErrors: fallback != nil,
+	}
+	h.ServeHTTP(recorder, r)
+
+	if recorder.failed {
+		// The headers of the error response must not be mixed with the cached ones.
+		clear(w.Header())
+		for name, values := range before {
+			w.Header()[name] = values
+		}
+		writeCached(w, r, *fallback, cacheStaleIfError)
+		return entry{}, false
+	}
+	if !recorder.buffering() {
+		return entry{}, false
+	}
+
+	cached := recorder.entry()
+	c.set(r, key, cached)
+	writeEntry(w, r, cached)
+	return cached, true
+}
+
+// revalidate refreshes the cached response in the background, unless it is already being refreshed.
+func (c Config) revalidate(r *http.Request, h http.Handler, key string, flights *flights) {
+	call, leader := flights.join(key, r)
+	if !leader {
+		return
+	}
+
+	// The request may be canceled once the stale response is sent.
+	background := r.Clone(context.WithoutCancel(r.Context()))
+	background = background.WithContext(context.WithValue(background.Context(), contextKeyTags, &[]string{}))
+	go func() {
+		defer flights.finish(key, call)
+		defer func() {
+			if err := recover(); err != nil {
+				slog.ErrorContext(background.Context(), "cache: panic while refreshing the cached response", "key", key, "panic", err)
 			}
+		}()
 
-			h.ServeHTTP(multiWriter, r)
+		recorder := &responseRecorder{
+			ResponseWriter: &discardWriter{header: http.Header{}},
+			before:         http.Header{},
+			ttl: func(status int, header http.Header) (lifetime, bool) {
+				return c.ttl(background, status, header)
+			},
+		}
+		h.ServeHTTP(recorder, background)
+		if !recorder.buffering() {
+			return
+		}
+		call.entry, call.ok = recorder.entry(), true
+		c.set(background, key, call.entry)
+	}()
+}
 
-			c.Storage.Set(key, multiWriter.cacheWriter.(*bytes.Buffer).String())
-			c.Storage.Set(key+"_response-content-type", multiWriter.Header().Get("Content-Type"))
-		})
+// pathTag is the tag of the responses to the requests to the path, whatever their query string.
+func pathTag(r *http.Request) string {
+	return "path:" + r.URL.Path
+}
+
+// defaultKey identifies the requests by their path and query string.
+func defaultKey(r *http.Request) string {
+	return "httpcache_" + r.URL.Path + "?" + r.URL.Query().Encode()
+}
+
+// get returns the response cached for the request.
+// The list of the headers the response varies on is stored at the key of the request,
+// and the response at a key including the values of these headers.
+func (c Config) get(r *http.Request, key string) (entry, bool) {
+	vary, ok, err := c.Storage.Get(r.Context(), key+"_vary")
+	if err != nil {
+		slog.ErrorContext(r.Context(), "cache: cannot get the cached response", "key", key, "error", err)
+	}
+	if !ok {
+		return entry{}, false
+	}
+
+	value, ok, err := c.Storage.Get(r.Context(), variantKey(r, key, string(vary)))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "cache: cannot get the cached response", "key", key, "error", err)
+	}
+	if !ok {
+		return entry{}, false
+	}
+
+	var cached entry
+	if err := json.Unmarshal(value, &cached); err != nil {
+		slog.ErrorContext(r.Context(), "cache: cannot decode the cached response", "key", key, "error", err)
+		return entry{}, false
+	}
+	if time.Now().After(cached.lastUse()) {
+		return entry{}, false
+	}
+	return cached, true
+}
+
+// set stores the response, tagged with the tags declared for the request and the path of the request.
+// Its variants are also tagged with the key of the request, so they are all deleted by invalidating this tag.
+func (c Config) set(r *http.Request, key string, cached entry) {
+	value, err := json.Marshal(cached)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "cache: cannot encode the response", "key", key, "error", err)
+		return
+	}
+
+	ttl := cached.lastUse().Sub(cached.Stored)
+	tags := append([]string{key, pathTag(r)}, requestTags(r)...)
+	vary := varyHeaders(cached.Header)
+	err = errors.Join(
+		c.Storage.Set(r.Context(), key+"_vary", []byte(vary), ttl, tags...),
+		c.Storage.Set(r.Context(), variantKey(r, key, vary), value, ttl, tags...),
+	)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "cache: cannot store the response", "key", key, "error", err)
+	}
+}
+
+// lifetime is the duration a response can be cached for, and then served while stale.
+type lifetime struct {
+	fresh                time.Duration
+	staleWhileRevalidate time.Duration
+	staleIfError         time.Duration
+}
+
+// ttl returns the lifetime of the response, or false if it must not be cached.
+func (c Config) ttl(r *http.Request, status int, header http.Header) (lifetime, bool) {
+	directives := parseCacheControl(header.Get("Cache-Control"))
+	for _, directive := range []string{"no-store", "no-cache", "private"} {
+		if _, ok := directives[directive]; ok {
+			return lifetime{}, false
+		}
+	}
+	if header.Get("Set-Cookie") != "" || varyHeaders(header) == "*" {
+		return lifetime{}, false
+	}
+
+	// Responses to authenticated requests are only shared when explicitly allowed.
+	_, public := directives["public"]
+	_, sharedMaxAge := directives["s-maxage"]
+	if r.Header.Get("Authorization") != "" && !public && !sharedMaxAge {
+		return lifetime{}, false
+	}
+
+	l := lifetime{
+		fresh:                c.TTL,
+		staleWhileRevalidate: c.StaleWhileRevalidate,
+		staleIfError:         c.StaleIfError,
+	}
+	if swr, ok := seconds(directives, "stale-while-revalidate"); ok {
+		l.staleWhileRevalidate = swr
+	}
+	if sie, ok := seconds(directives, "stale-if-error"); ok {
+		l.staleIfError = sie
+	}
+
+	age, explicit := maxAge(directives)
+	if explicit {
+		l.fresh = age
+	} else if !cacheableByDefault[status] {
+		return lifetime{}, false
 	}
+	return l, l.fresh > 0 || l.staleWhileRevalidate > 0 || l.staleIfError > 0
+}
+
+// writeCached sends the cached response, with its age.
+func writeCached(w http.ResponseWriter, r *http.Request, cached entry, status string) {
+	w.Header().Set("Cache", status)
+	w.Header().Set("Age", strconv.Itoa(int(time.Since(cached.Stored).Seconds())))
+	writeEntry(w, r, cached)
+}
+
+// writeEntry sends the cached response, or 304 Not Modified if the client already has it.
+func writeEntry(w http.ResponseWriter, r *http.Request, cached entry) {
+	for name, values := range cached.Header {
+		w.Header()[name] = values
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), cached.Header.Get("ETag")) {
+		for name := range w.Header() {
+			if isContentHeader(name) {
+				w.Header().Del(name)
+			}
+		}
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(cached.Body)))
+	w.WriteHeader(cached.Status)
+	_, _ = w.Write(cached.Body)
 }
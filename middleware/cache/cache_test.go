@@ -3,6 +3,10 @@ package cache
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -199,6 +203,265 @@ func TestCache(t *testing.T) {
 	})
 }
 
+func TestHTTPSemantics(t *testing.T) {
+	calls := 0
+	handler := New(Config{TTL: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if cacheControl := r.URL.Query().Get("cache-control"); cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+		w.Header().Set("Vary", "Accept-Language")
+		w.Header().Set("X-Custom", "custom")
+		w.Header().Set("Content-Type", "text/plain")
+		status, _ := strconv.Atoi(r.URL.Query().Get("status"))
+		if status != 0 {
+			w.WriteHeader(status)
+		}
+		_, _ = w.Write([]byte("hello " + r.Header.Get("Accept-Language") + " " + strconv.Itoa(calls)))
+	}))
+
+	get := func(target string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		w.Header().Set("X-Request-ID", target)
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("full response is cached", func(t *testing.T) {
+		w := get("/full?status=404")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "hello  1", w.Body.String())
+		require.Equal(t, "miss", w.Header().Get("Cache"))
+
+		w = get("/full?status=404")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "hello  1", w.Body.String())
+		require.Equal(t, "hit", w.Header().Get("Cache"))
+		require.Equal(t, "custom", w.Header().Get("X-Custom"))
+		require.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+		require.Equal(t, "0", w.Header().Get("Age"))
+		require.Equal(t, "/full?status=404", w.Header().Get("X-Request-ID"), "headers set outside the cache are not replaced")
+	})
+
+	t.Run("status not cacheable by default", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, get("/created?status=201").Code)
+		w := get("/created?status=201")
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, "miss", w.Header().Get("Cache"))
+
+		get("/created?status=201&cache-control=max-age=60")
+		require.Equal(t, "hit", get("/created?status=201&cache-control=max-age=60").Header().Get("Cache"), "explicit freshness")
+	})
+
+	t.Run("query string is part of the key", func(t *testing.T) {
+		get("/query?a=1&b=2")
+		require.Equal(t, "hit", get("/query?b=2&a=1").Header().Get("Cache"))
+		require.Equal(t, "miss", get("/query?a=2&b=2").Header().Get("Cache"))
+	})
+
+	t.Run("Vary headers are part of the key", func(t *testing.T) {
+		fr := get("/vary", "Accept-Language", "fr").Body.String()
+		en := get("/vary", "Accept-Language", "en").Body.String()
+		require.NotEqual(t, fr, en)
+
+		w := get("/vary", "Accept-Language", "fr")
+		require.Equal(t, "hit", w.Header().Get("Cache"))
+		require.Equal(t, fr, w.Body.String())
+		require.Equal(t, en, get("/vary", "Accept-Language", "en").Body.String())
+	})
+
+	t.Run("Cache-Control of the response", func(t *testing.T) {
+		for _, cacheControl := range []string{"no-store", "private, max-age=60", "max-age=0", "no-cache"} {
+			target := "/cache-control?cache-control=" + url.QueryEscape(cacheControl)
+			get(target)
+			require.Equal(t, "miss", get(target).Header().Get("Cache"), cacheControl)
+		}
+
+		get("/cache-control?cache-control=public,max-age=60")
+		require.Equal(t, "hit", get("/cache-control?cache-control=public,max-age=60").Header().Get("Cache"))
+	})
+
+	t.Run("max-age expiration", func(t *testing.T) {
+		handler := New(Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "max-age=1")
+			_, _ = w.Write([]byte("expires"))
+		}))
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, "hit", w.Header().Get("Cache"))
+
+		time.Sleep(1100 * time.Millisecond)
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, "miss", w.Header().Get("Cache"))
+	})
+
+	t.Run("authenticated requests", func(t *testing.T) {
+		get("/auth", "Authorization", "Bearer token")
+		require.Equal(t, "miss", get("/auth", "Authorization", "Bearer token").Header().Get("Cache"))
+	})
+
+	t.Run("ETag and If-None-Match", func(t *testing.T) {
+		w := get("/etag")
+		etag := w.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		w = get("/etag", "If-None-Match", etag)
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+		require.Equal(t, etag, w.Header().Get("ETag"))
+		require.Empty(t, w.Header().Get("Content-Type"))
+
+		w = get("/etag-first-request", "If-None-Match", `"other", W/`+computeETag([]byte("hello  "+strconv.Itoa(calls+1))))
+		require.Equal(t, http.StatusNotModified, w.Code, "answered on the first request too")
+
+		w = get("/etag", "If-None-Match", `"other"`)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, etag, w.Header().Get("ETag"))
+	})
+}
+
+func TestCoalescing(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	handler := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+		w.Header().Set("Vary", "Accept")
+		_, _ = w.Write([]byte("recipes for " + r.Header.Get("Accept")))
+	}))
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+		r.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 6)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		responses[0] = get("application/json")
+	}()
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	for i := 1; i < len(responses); i++ {
+		accept := "application/json"
+		if i == len(responses)-1 {
+			accept = "application/xml"
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = get(accept)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond) // let the requests join the call
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(2), calls.Load(), "one call, and one for the request with another Accept header")
+	require.Equal(t, "miss", responses[0].Header().Get("Cache"))
+	for _, w := range responses[1 : len(responses)-1] {
+		require.Equal(t, "coalesced", w.Header().Get("Cache"))
+		require.Equal(t, "recipes for application/json", w.Body.String())
+	}
+	require.Equal(t, "recipes for application/xml", responses[len(responses)-1].Body.String())
+}
+
+func TestStale(t *testing.T) {
+	t.Run("stale-while-revalidate", func(t *testing.T) {
+		var calls atomic.Int32
+		handler := New(Config{TTL: 50 * time.Millisecond, StaleWhileRevalidate: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("version " + strconv.Itoa(int(calls.Add(1)))))
+		}))
+		get := func() *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes", nil))
+			return w
+		}
+
+		require.Equal(t, "version 1", get().Body.String())
+		time.Sleep(60 * time.Millisecond)
+
+		w := get()
+		require.Equal(t, "stale", w.Header().Get("Cache"))
+		require.Equal(t, "version 1", w.Body.String())
+
+		require.Eventually(t, func() bool {
+			w := get()
+			return w.Header().Get("Cache") == "hit" && w.Body.String() == "version 2"
+		}, time.Second, 5*time.Millisecond)
+		require.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("stale-if-error from the response", func(t *testing.T) {
+		var failing atomic.Bool
+		handler := New()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if failing.Load() {
+				w.Header().Set("X-Error", "true")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = w.Write([]byte("unavailable"))
+				return
+			}
+			w.Header().Set("Cache-Control", "max-age=0, stale-if-error=60")
+			_, _ = w.Write([]byte("recipes"))
+		}))
+		get := func() *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes", nil))
+			return w
+		}
+
+		require.Equal(t, "miss", get().Header().Get("Cache"))
+		require.Equal(t, "miss", get().Header().Get("Cache"), "expired")
+
+		failing.Store(true)
+		w := get()
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "stale-if-error", w.Header().Get("Cache"))
+		require.Equal(t, "recipes", w.Body.String())
+		require.Empty(t, w.Header().Get("X-Error"))
+	})
+
+	t.Run("errors are sent without stale response", func(t *testing.T) {
+		handler := New(Config{StaleIfError: time.Minute})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/recipes", nil))
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+func TestCacheControlHelpers(t *testing.T) {
+	directives := parseCacheControl(`public, Max-Age="60", s-maxage=10`)
+	require.Equal(t, map[string]string{"public": "", "max-age": "60", "s-maxage": "10"}, directives)
+
+	age, ok := maxAge(directives)
+	require.True(t, ok)
+	require.Equal(t, 10*time.Second, age)
+
+	_, ok = maxAge(parseCacheControl("no-cache"))
+	require.False(t, ok)
+
+	header := http.Header{}
+	header.Add("Vary", "accept-language, Accept")
+	header.Add("Vary", "Accept")
+	require.Equal(t, "Accept,Accept-Language", varyHeaders(header))
+	header.Add("Vary", "*")
+	require.Equal(t, "*", varyHeaders(header))
+}
+
 func BenchmarkCache(b *testing.B) {
 	s := fuego.NewServer()
 
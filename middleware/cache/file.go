@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStorage is a [Storage] keeping the entries in files of a directory.
+// The cache survives restarts, and can be shared by the servers of a machine or mounting the same volume.
+//
+// Each entry is a file named after the hash of its key, starting with its expiration date.
+// Tags are directories containing an empty file for each key tagged.
+// At most once per minute, Set deletes the expired entries, and the tags of the deleted entries.
+type FileStorage struct {
+	dir string
+
+	mu        sync.Mutex // Held while sweeping.
+	nextSweep time.Time
+}
+
+// sweepInterval is the minimum duration between two sweeps of a [FileStorage].
+const sweepInterval = time.Minute
+
+var _ Storage = (*FileStorage)(nil)
+
+// NewFileStorage returns a [FileStorage] in the directory, created if needed.
+func NewFileStorage(dir string) (*FileStorage, error) {
+	for _, subdir := range []string{"entries", "tags"} {
+		if err := os.MkdirAll(filepath.Join(dir, subdir), 0o750); err != nil {
+			return nil, fmt.Errorf("cannot create the cache directory: %w", err)
+		}
+	}
+	return &FileStorage{dir: dir}, nil
+}
+
+func (f *FileStorage) Get(_ context.Context, key string) ([]byte, bool, error) {
+	path := f.entryPath(key)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read cache entry: %w", err)
+	}
+	if len(content) < 8 {
+		return nil, false, fmt.Errorf("invalid cache entry %s", path)
+	}
+
+	if expired(content) {
+		_ = os.Remove(path)
+		return nil, false, nil
+	}
+	return content[8:], true, nil
+}
+
+// expired reports whether the entry starting with the content is expired.
+func expired(content []byte) bool {
+	expires := int64(binary.BigEndian.Uint64(content[:8]))
+	return expires != 0 && time.Now().UnixNano() > expires
+}
+
+func (f *FileStorage) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	var expires int64
+	if ttl > 0 {
+		expires = time.Now().Add(ttl).UnixNano()
+	}
+	content := make([]byte, 8, 8+len(value))
+	binary.BigEndian.PutUint64(content, uint64(expires))
+	content = append(content, value...)
+
+	// Written to a temporary file then renamed, so readers never see a partial entry.
+	tmp, err := os.CreateTemp(filepath.Join(f.dir, "entries"), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("cannot write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.entryPath(key)); err != nil {
+		return fmt.Errorf("cannot write cache entry: %w", err)
+	}
+
+	for _, tag := range tags {
+		tagDir := f.tagPath(tag)
+		if err := os.MkdirAll(tagDir, 0o750); err != nil {
+			return fmt.Errorf("cannot tag cache entry: %w", err)
+		}
+		if err := os.WriteFile(filepath.Join(tagDir, hash(key)), nil, 0o640); err != nil {
+			return fmt.Errorf("cannot tag cache entry: %w", err)
+		}
+	}
+
+	f.sweepIfDue()
+	return nil
+}
+
+// sweepIfDue sweeps the storage, unless it was swept less than [sweepInterval] ago or is being swept.
+func (f *FileStorage) sweepIfDue() {
+	if !f.mu.TryLock() {
+		return
+	}
+	defer f.mu.Unlock()
+	if time.Now().Before(f.nextSweep) {
+		return
+	}
+	f.nextSweep = time.Now().Add(sweepInterval)
+	f.sweep()
+}
+
+// sweep deletes the expired entries, then the tags of the entries that do not exist anymore.
+// It is best effort: the files that cannot be read or deleted are left for the next sweep.
+func (f *FileStorage) sweep() {
+	entriesDir := filepath.Join(f.dir, "entries")
+	entries, _ := os.ReadDir(entriesDir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".tmp-") {
+			continue // Being written.
+		}
+		if expiredFile(filepath.Join(entriesDir, entry.Name())) {
+			_ = f.remove(entry.Name())
+		}
+	}
+
+	tagsDir := filepath.Join(f.dir, "tags")
+	tags, _ := os.ReadDir(tagsDir)
+	for _, tag := range tags {
+		tagDir := filepath.Join(tagsDir, tag.Name())
+		tagged, _ := os.ReadDir(tagDir)
+		for _, file := range tagged {
+			if _, err := os.Stat(filepath.Join(entriesDir, file.Name())); errors.Is(err, fs.ErrNotExist) {
+				_ = os.Remove(filepath.Join(tagDir, file.Name()))
+			}
+		}
+		// Fails if the tag is not empty, including when an entry was tagged since it was read.
+		_ = os.Remove(tagDir)
+	}
+}
+
+// expiredFile reports whether the entry file is expired, reading only its expiration date.
+func expiredFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return false
+	}
+	return expired(header)
+}
+
+func (f *FileStorage) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := f.remove(hash(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FileStorage) InvalidateTags(_ context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tagDir := f.tagPath(tag)
+		tagged, err := os.ReadDir(tagDir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read cache tag: %w", err)
+		}
+		for _, file := range tagged {
+			if err := f.remove(file.Name()); err != nil {
+				return err
+			}
+		}
+		if err := os.RemoveAll(tagDir); err != nil {
+			return fmt.Errorf("cannot delete cache tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// remove deletes the entry with the given hashed key.
+func (f *FileStorage) remove(hashedKey string) error {
+	err := os.Remove(filepath.Join(f.dir, "entries", hashedKey))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("cannot delete cache entry: %w", err)
+	}
+	return nil
+}
+
+func (f *FileStorage) entryPath(key string) string {
+	return filepath.Join(f.dir, "entries", hash(key))
+}
+
+func (f *FileStorage) tagPath(tag string) string {
+	return filepath.Join(f.dir, "tags", hash(tag))
+}
+
+// hash returns a file name for the key, whatever its characters and length.
+func hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
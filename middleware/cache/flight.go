@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"net/http"
+	"sync"
+)
+
+// flight is a call of the handler for a key, shared by the concurrent requests for this key.
+type flight struct {
+	done    chan struct{}
+	request *http.Request // request of the call
+	entry   entry         // response of the call, if ok
+	ok      bool          // whether the response was cached
+}
+
+// sharedWith returns the response of the call, if it can be sent for the request:
+// the request must have the same values as the one of the call for the headers the response varies on.
+func (f *flight) sharedWith(r *http.Request, key string) (entry, bool) {
+	if !f.ok {
+		return entry{}, false
+	}
+	vary := varyHeaders(f.entry.Header)
+	if variantKey(r, key, vary) != variantKey(f.request, key, vary) {
+		return entry{}, false
+	}
+	return f.entry, true
+}
+
+// flights coalesces the concurrent calls of the handler for the same key.
+type flights struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// join returns the call in progress for the key, or starts a new one if there is none.
+// The leader, starting the call, must call finish when done.
+func (f *flights) join(key string, r *http.Request) (call *flight, leader bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if call, ok := f.calls[key]; ok {
+		return call, false
+	}
+	call = &flight{done: make(chan struct{}), request: r}
+	f.calls[key] = call
+	return call, true
+}
+
+// finish ends the call, and releases the requests waiting for its response.
+func (f *flights) finish(key string, call *flight) {
+	f.mu.Lock()
+	delete(f.calls, key)
+	f.mu.Unlock()
+	close(call.done)
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheableByDefault are the status codes that can be cached without explicit freshness,
+// see https://www.rfc-editor.org/rfc/rfc9110#section-15.1
+var cacheableByDefault = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// excludedHeaders are specific to a response, and never cached.
+var excludedHeaders = map[string]bool{
+	"Age":            true,
+	"Cache":          true,
+	"Connection":     true,
+	"Content-Length": true,
+	"Date":           true,
+	"Server-Timing":  true,
+	"Trailer":        true,
+}
+
+// parseCacheControl returns the directives of a Cache-Control header, with their lowercase name.
+func parseCacheControl(header string) map[string]string {
+	directives := map[string]string{}
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name == "" {
+			continue
+		}
+		directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+	}
+	return directives
+}
+
+// maxAge returns the freshness lifetime set by the directives, if any.
+// s-maxage applies to shared caches, and takes precedence over max-age.
+func maxAge(directives map[string]string) (time.Duration, bool) {
+	if age, ok := seconds(directives, "s-maxage"); ok {
+		return age, true
+	}
+	return seconds(directives, "max-age")
+}
+
+// seconds returns the duration set by a directive in seconds, if present.
+// Invalid durations are considered as 0.
+func seconds(directives map[string]string, directive string) (time.Duration, bool) {
+	value, ok := directives[directive]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, true
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// varyHeaders returns the sorted, canonical list of the request headers the response varies on.
+func varyHeaders(header http.Header) string {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return "*"
+			}
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	slices.Sort(names)
+	return strings.Join(slices.Compact(names), ",")
+}
+
+// variantKey returns the key of the response for the values of the request headers it varies on.
+func variantKey(r *http.Request, key, vary string) string {
+	if vary == "" {
+		return key
+	}
+	values := url.Values{}
+	for _, name := range strings.Split(vary, ",") {
+		values.Set(name, strings.Join(r.Header.Values(name), ","))
+	}
+	return key + "_vary_" + values.Encode()
+}
+
+// computeETag returns a strong ETag for the body.
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether the If-None-Match header matches the ETag, using the weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// isContentHeader reports whether the header describes the content, not sent with 304 Not Modified.
+func isContentHeader(name string) bool {
+	return strings.HasPrefix(http.CanonicalHeaderKey(name), "Content-")
+}
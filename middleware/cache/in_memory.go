@@ -1,27 +1,101 @@
 package cache
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/golang-lru/v2/expirable"
 )
 
+type memoryEntry struct {
+	value   []byte
+	expires time.Time // zero if the entry does not expire before the duration of the cache
+	tags    []string
+}
+
+// TTLCache is an in-memory [Storage], local to the server.
 type TTLCache struct {
-	cache *expirable.LRU[string, string]
+	cache *expirable.LRU[string, memoryEntry]
+
+	mu   sync.Mutex
+	tags map[string]map[string]struct{} // keys by tag
 }
 
 var _ Storage = (*TTLCache)(nil)
 
+// NewInMemoryCache returns a [TTLCache] keeping maxObjects entries,
+// for at most the given duration, whatever their ttl.
 func NewInMemoryCache(duration time.Duration, maxObjects int) *TTLCache {
-	return &TTLCache{
-		cache: expirable.NewLRU[string, string](maxObjects, nil, duration),
+	t := &TTLCache{
+		tags: make(map[string]map[string]struct{}),
 	}
+	// Called by the LRU under its lock: t.mu must never be held while calling the LRU.
+	t.cache = expirable.NewLRU(maxObjects, func(key string, entry memoryEntry) {
+		t.untag(key, entry.tags)
+	}, duration)
+	return t
 }
 
-func (t *TTLCache) Get(key string) (string, bool) {
-	return t.cache.Get(key)
+func (t *TTLCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	entry, ok := t.cache.Get(key)
+	if !ok || (!entry.expires.IsZero() && time.Now().After(entry.expires)) {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
 }
 
-func (t *TTLCache) Set(key string, value string) {
-	t.cache.Add(key, value)
+func (t *TTLCache) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	entry := memoryEntry{value: value, tags: tags}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+
+	if previous, ok := t.cache.Peek(key); ok {
+		t.untag(key, previous.tags)
+	}
+	t.cache.Add(key, entry)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tag := range tags {
+		if t.tags[tag] == nil {
+			t.tags[tag] = make(map[string]struct{})
+		}
+		t.tags[tag][key] = struct{}{}
+	}
+	return nil
+}
+
+func (t *TTLCache) Delete(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		t.cache.Remove(key)
+	}
+	return nil
+}
+
+func (t *TTLCache) InvalidateTags(ctx context.Context, tags ...string) error {
+	var keys []string
+	t.mu.Lock()
+	for _, tag := range tags {
+		for key := range t.tags[tag] {
+			keys = append(keys, key)
+		}
+		delete(t.tags, tag)
+	}
+	t.mu.Unlock()
+
+	return t.Delete(ctx, keys...)
+}
+
+// untag removes the key from the index of the tags.
+func (t *TTLCache) untag(key string, tags []string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tag := range tags {
+		delete(t.tags[tag], key)
+		if len(t.tags[tag]) == 0 {
+			delete(t.tags, tag)
+		}
+	}
 }
@@ -0,0 +1,150 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"sync"
+)
+
+// storages are the storages of the cache middlewares created with [New], from which [Invalidate] deletes the tagged responses.
+// A storage used by several middlewares is registered once.
+var storages struct {
+	mu   sync.Mutex
+	list []Storage
+}
+
+// registerStorage adds the storage to the ones used by [Invalidate], unless already present.
+func registerStorage(storage Storage) {
+	storages.mu.Lock()
+	defer storages.mu.Unlock()
+	if reflect.TypeOf(storage).Comparable() {
+		for _, registered := range storages.list {
+			if reflect.TypeOf(registered).Comparable() && registered == storage {
+				return
+			}
+		}
+	}
+	storages.list = append(storages.list, storage)
+}
+
+// Invalidate deletes the cached responses with any of the tags, from the storages of all the cache middlewares.
+// The responses are tagged with the tags declared with [Tags], and with "path:" followed by the path of the request.
+// Example, after updating a recipe outside of a request:
+//
+//	err := cache.Invalidate(ctx, "recipes", "path:/recipes/"+id)
+//
+// Tags are shared by the whole application: servers of the same process using the same tags purge each other's responses.
+// To purge a single storage, call its InvalidateTags method.
+func Invalidate(ctx context.Context, tags ...string) error {
+	storages.mu.Lock()
+	list := slices.Clone(storages.list)
+	storages.mu.Unlock()
+
+	var errs []error
+	for _, storage := range list {
+		errs = append(errs, storage.InvalidateTags(ctx, tags...))
+	}
+	return errors.Join(errs...)
+}
+
+type contextKey string
+
+const contextKeyTags contextKey = "cacheTags"
+
+// Tags tags the responses cached for the route, so they can be deleted by the mutating routes declaring them
+// with [Invalidates], or with [Invalidate].
+// The path parameters of the tags, between braces, are replaced by their value in the request.
+// Example:
+//
+//	fuego.Get(s, "/recipes", listRecipes, cache.New(), cache.Tags("recipes"))
+//	fuego.Get(s, "/recipes/{id}", getRecipe, cache.New(), cache.Tags("recipes", "recipe:{id}"))
+func Tags(tags ...string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, withTags(r, resolveTags(r, tags)))
+		})
+	}
+}
+
+// Invalidates deletes the cached responses with any of the tags with [Invalidate], after a successful POST, PUT, PATCH
+// or DELETE request to the route. The route does not need to be served by a cache middleware.
+// The path parameters of the tags, between braces, are replaced by their value in the request.
+// Example:
+//
+//	fuego.Post(s, "/recipes/new", createRecipe, cache.Invalidates("recipes"))
+//	fuego.Put(s, "/recipes/{id}", updateRecipe, cache.Invalidates("recipes", "recipe:{id}"))
+func Invalidates(tags ...string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isUnsafe(r.Method) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			resolved := resolveTags(r, tags)
+			invalidateAfter(w, r, h, resolved, func(ctx context.Context) error {
+				return Invalidate(ctx, resolved...)
+			})
+		})
+	}
+}
+
+// invalidateAfter serves the request, then calls invalidate if it succeeded.
+func invalidateAfter(w http.ResponseWriter, r *http.Request, h http.Handler, tags []string, invalidate func(context.Context) error) {
+	statusWriter := &MultiHTTPWriter{ResponseWriter: w, cacheWriter: io.Discard}
+	h.ServeHTTP(statusWriter, r)
+	if statusWriter.status >= http.StatusBadRequest {
+		return
+	}
+
+	if err := invalidate(r.Context()); err != nil {
+		slog.ErrorContext(r.Context(), "cache: cannot invalidate the cached responses", "tags", tags, "error", err)
+	}
+}
+
+// withTags adds the tags to the ones of the request context.
+// The cache middleware reads them after the handler, whether it runs before or after the middleware adding them.
+func withTags(r *http.Request, tags []string) *http.Request {
+	if collected, ok := r.Context().Value(contextKeyTags).(*[]string); ok {
+		*collected = append(*collected, tags...)
+		return r
+	}
+	return r.WithContext(context.WithValue(r.Context(), contextKeyTags, &tags))
+}
+
+// requestTags returns the tags declared with [Tags] for the request.
+func requestTags(r *http.Request) []string {
+	tags, ok := r.Context().Value(contextKeyTags).(*[]string)
+	if !ok {
+		return nil
+	}
+	return *tags
+}
+
+var pathParam = regexp.MustCompile(`\{([^{}]+)\}`)
+
+// resolveTags replaces the path parameters of the tags by their value.
+func resolveTags(r *http.Request, tags []string) []string {
+	resolved := make([]string, len(tags))
+	for i, tag := range tags {
+		resolved[i] = pathParam.ReplaceAllStringFunc(tag, func(param string) string {
+			return r.PathValue(param[1 : len(param)-1])
+		})
+	}
+	return resolved
+}
+
+// isUnsafe reports whether the method may change the resources, see https://www.rfc-editor.org/rfc/rfc9110#section-9.2.1
+func isUnsafe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+func TestInvalidation(t *testing.T) {
+	recipes := []string{"pizza"}
+
+	s := fuego.NewServer()
+	fuego.Use(s, New())
+	fuego.Get(s, "/recipes", func(c *fuego.ContextNoBody) ([]string, error) {
+		return recipes, nil
+	}, Tags("recipes"))
+	fuego.Get(s, "/recipes/{id}", func(c *fuego.ContextNoBody) (string, error) {
+		id, _ := strconv.Atoi(c.PathParam("id"))
+		if id >= len(recipes) {
+			return "", fuego.NotFoundError{}
+		}
+		return recipes[id], nil
+	}, Tags("recipes", "recipe:{id}"))
+	fuego.Post(s, "/recipes/new", func(c *fuego.ContextWithBody[string]) (string, error) {
+		body, err := c.Body()
+		if err != nil {
+			return "", err
+		}
+		recipes = append(recipes, body)
+		return body, nil
+	}, Invalidates("recipes"))
+	fuego.Put(s, "/recipes/{id}", func(c *fuego.ContextWithBody[string]) (string, error) {
+		id, _ := strconv.Atoi(c.PathParam("id"))
+		if id >= len(recipes) {
+			return "", fuego.NotFoundError{}
+		}
+		recipes[id], _ = c.Body()
+		return recipes[id], nil
+	})
+	fuego.Post(s, "/recipes/{id}/rename", func(c *fuego.ContextWithBody[string]) (string, error) {
+		id, _ := strconv.Atoi(c.PathParam("id"))
+		recipes[id], _ = c.Body()
+		return recipes[id], nil
+	}, Invalidates("recipe:{id}"))
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, nil)
+		if body != "" {
+			r = httptest.NewRequest(method, path, strings.NewReader(`"`+body+`"`))
+			r.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+	requireCached := func(t *testing.T, path, body string, cached bool) {
+		t.Helper()
+		w := request(http.MethodGet, path, "")
+		require.Contains(t, w.Body.String(), body)
+		if cached {
+			require.Equal(t, "hit", w.Header().Get("Cache"))
+		} else {
+			require.Equal(t, "miss", w.Header().Get("Cache"))
+		}
+	}
+
+	t.Run("mutating routes invalidate their tags", func(t *testing.T) {
+		requireCached(t, "/recipes", "pizza", false)
+		requireCached(t, "/recipes", "pizza", true)
+
+		require.Equal(t, http.StatusOK, request(http.MethodPost, "/recipes/new", "pasta").Code)
+		requireCached(t, "/recipes", "pasta", false)
+	})
+
+	t.Run("failed requests do not invalidate", func(t *testing.T) {
+		requireCached(t, "/recipes", "pasta", true)
+		require.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/recipes/new", "").Code)
+		requireCached(t, "/recipes", "pasta", true)
+	})
+
+	t.Run("path parameters in tags", func(t *testing.T) {
+		requireCached(t, "/recipes/0", "pizza", false)
+		requireCached(t, "/recipes/1", "pasta", false)
+
+		request(http.MethodPost, "/recipes/0/rename", "margherita")
+		requireCached(t, "/recipes/0", "margherita", false)
+		requireCached(t, "/recipes/1", "pasta", true)
+	})
+
+	t.Run("mutations invalidate their path", func(t *testing.T) {
+		requireCached(t, "/recipes/1?lang=en", "pasta", false)
+		requireCached(t, "/recipes/1?lang=en", "pasta", true)
+
+		require.Equal(t, http.StatusOK, request(http.MethodPut, "/recipes/1", "carbonara").Code)
+		requireCached(t, "/recipes/1", "carbonara", false)
+		requireCached(t, "/recipes/1?lang=en", "carbonara", false)
+		requireCached(t, "/recipes", "pizza", true) // other paths are not invalidated
+	})
+
+	t.Run("programmatic invalidation", func(t *testing.T) {
+		requireCached(t, "/recipes/0", "margherita", true)
+		recipes[0] = "calzone"
+
+		require.NoError(t, Invalidate(context.Background(), "recipe:0"))
+		requireCached(t, "/recipes/0", "calzone", false)
+		requireCached(t, "/recipes", "pizza", true)
+	})
+}
+
+func TestInvalidationWithoutCacheOnTheRoute(t *testing.T) {
+	recipes := []string{"pizza"}
+
+	s := fuego.NewServer()
+	fuego.Get(s, "/recipes", func(c *fuego.ContextNoBody) ([]string, error) {
+		return recipes, nil
+	}, New(), Tags("recipes"))
+	fuego.Post(s, "/recipes/new", func(c *fuego.ContextNoBody) (string, error) {
+		recipes = append(recipes, "pasta")
+		return "pasta", nil
+	}, Invalidates("recipes"))
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	require.Equal(t, "miss", request(http.MethodGet, "/recipes").Header().Get("Cache"))
+	require.Equal(t, "hit", request(http.MethodGet, "/recipes").Header().Get("Cache"))
+
+	require.Equal(t, http.StatusOK, request(http.MethodPost, "/recipes/new").Code)
+	w := request(http.MethodGet, "/recipes")
+	require.Equal(t, "miss", w.Header().Get("Cache"))
+	require.Contains(t, w.Body.String(), "pasta")
+}
@@ -0,0 +1,319 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+type RedisConfig struct {
+	Addr     string        // Address of the server. Defaults to localhost:6379.
+	Username string        // Username for AUTH, if any.
+	Password string        // Password for AUTH. No authentication if empty.
+	DB       int           // Database selected with SELECT.
+	Prefix   string        // Prefix of the keys, to share a server with other applications. Defaults to "fuego:cache:".
+	PoolSize int           // Maximum number of idle connections kept open. Defaults to 10.
+	Timeout  time.Duration // Timeout of the connection and of each command, when the context has no deadline. Defaults to 5 seconds.
+}
+
+// RedisStorage is a [Storage] on a server speaking the Redis protocol (RESP): Redis, Valkey, KeyDB, Dragonfly...
+// The servers using the same Redis server share their cache.
+// Tags are sets containing the keys tagged. They expire with the last key tagged: the PEXPIRE options NX and GT
+// require Redis 7, or a server compatible with them.
+type RedisStorage struct {
+	config RedisConfig
+	idle   chan *redisConn
+}
+
+var _ Storage = (*RedisStorage)(nil)
+
+// NewRedisStorage returns a [RedisStorage]. Connections are opened when needed.
+func NewRedisStorage(config RedisConfig) *RedisStorage {
+	if config.Addr == "" {
+		config.Addr = "localhost:6379"
+	}
+	if config.Prefix == "" {
+		config.Prefix = "fuego:cache:"
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 10
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &RedisStorage{
+		config: config,
+		idle:   make(chan *redisConn, config.PoolSize),
+	}
+}
+
+func (s *RedisStorage) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	replies, err := s.do(ctx, []string{"GET", s.config.Prefix + key})
+	if err != nil {
+		return nil, false, err
+	}
+	if replies[0] == nil {
+		return nil, false, nil
+	}
+	value, ok := replies[0].([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("redis: unexpected reply to GET: %v", replies[0])
+	}
+	return value, true, nil
+}
+
+func (s *RedisStorage) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error {
+	set := []string{"SET", s.config.Prefix + key, string(value)}
+	if ttl <= 0 {
+		// The keys without TTL are tagged in sets without TTL, that an expiring key must not make expire.
+		commands := [][]string{set}
+		for _, tag := range tags {
+			commands = append(commands, []string{"SADD", s.persistentTagKey(tag), s.config.Prefix + key})
+		}
+		_, err := s.do(ctx, commands...)
+		return err
+	}
+
+	ms := strconv.FormatInt(max(ttl.Milliseconds(), 1), 10)
+	commands := [][]string{append(set, "PX", ms)}
+	for _, tag := range tags {
+		// NX sets the TTL of a new set, GT extends the TTL of an existing one: it never expires before its keys.
+		commands = append(commands,
+			[]string{"SADD", s.tagKey(tag), s.config.Prefix + key},
+			[]string{"PEXPIRE", s.tagKey(tag), ms, "NX"},
+			[]string{"PEXPIRE", s.tagKey(tag), ms, "GT"},
+		)
+	}
+	_, err := s.do(ctx, commands...)
+	return err
+}
+
+func (s *RedisStorage) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	del := []string{"DEL"}
+	for _, key := range keys {
+		del = append(del, s.config.Prefix+key)
+	}
+	_, err := s.do(ctx, del)
+	return err
+}
+
+func (s *RedisStorage) InvalidateTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		replies, err := s.do(ctx,
+			[]string{"SMEMBERS", s.tagKey(tag)},
+			[]string{"SMEMBERS", s.persistentTagKey(tag)},
+		)
+		if err != nil {
+			return err
+		}
+
+		del := []string{"DEL", s.tagKey(tag), s.persistentTagKey(tag)}
+		for _, reply := range replies {
+			members, _ := reply.([]any)
+			for _, member := range members {
+				if key, ok := member.([]byte); ok {
+					del = append(del, string(key))
+				}
+			}
+		}
+		if _, err := s.do(ctx, del); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the idle connections.
+func (s *RedisStorage) Close() error {
+	for {
+		select {
+		case conn := <-s.idle:
+			conn.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (s *RedisStorage) tagKey(tag string) string {
+	return s.config.Prefix + "tag:" + tag
+}
+
+func (s *RedisStorage) persistentTagKey(tag string) string {
+	return s.config.Prefix + "persistent-tag:" + tag
+}
+
+// do sends the commands in a pipeline, and returns their replies.
+func (s *RedisStorage) do(ctx context.Context, commands ...[]string) ([]any, error) {
+	conn, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.config.Timeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	replies, err := conn.pipeline(commands...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection is in an unknown state.
+		conn.Close()
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	select {
+	case s.idle <- conn:
+	default:
+		conn.Close()
+	}
+	return replies, err
+}
+
+// conn returns an idle connection, or opens a new one.
+func (s *RedisStorage) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-s.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: s.config.Timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", s.config.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	conn := &redisConn{Conn: netConn, reader: bufio.NewReader(netConn)}
+
+	var setup [][]string
+	if s.config.Password != "" {
+		auth := []string{"AUTH", s.config.Password}
+		if s.config.Username != "" {
+			auth = []string{"AUTH", s.config.Username, s.config.Password}
+		}
+		setup = append(setup, auth)
+	}
+	if s.config.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(s.config.DB)})
+	}
+	if len(setup) > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.config.Timeout))
+		if _, err := conn.pipeline(setup...); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("redis: cannot set up the connection: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// redisError is an error reply of the server.
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+type redisConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// pipeline sends the commands, then reads their replies.
+// The first error reply is returned after all the replies are read.
+func (c *redisConn) pipeline(commands ...[]string) ([]any, error) {
+	writer := bufio.NewWriter(c.Conn)
+	for _, command := range commands {
+		writeCommand(writer, command)
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, err
+	}
+
+	replies := make([]any, len(commands))
+	var replyErr error
+	for i := range commands {
+		reply, err := readReply(c.reader)
+		if err != nil {
+			return nil, err
+		}
+		if err, ok := reply.(redisError); ok && replyErr == nil {
+			replyErr = err
+		}
+		replies[i] = reply
+	}
+	return replies, replyErr
+}
+
+// writeCommand writes the command as an array of bulk strings.
+func writeCommand(w *bufio.Writer, command []string) {
+	w.WriteString("*" + strconv.Itoa(len(command)) + "\r\n")
+	for _, arg := range command {
+		w.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n")
+		w.WriteString(arg)
+		w.WriteString("\r\n")
+	}
+}
+
+// readReply reads a RESP2 value: a string, a redisError, an int64, a []byte, nil or a []any.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("invalid reply %q", line)
+	}
+	kind, content := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return content, nil
+	case '-':
+		return redisError(content), nil
+	case ':':
+		return strconv.ParseInt(content, 10, 64)
+	case '$':
+		length, err := strconv.Atoi(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk string length %q", content)
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		value := make([]byte, length+2)
+		if _, err := io.ReadFull(r, value); err != nil {
+			return nil, err
+		}
+		return value[:length], nil
+	case '*':
+		length, err := strconv.Atoi(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid array length %q", content)
+		}
+		if length < 0 {
+			return nil, nil
+		}
+		values := make([]any, length)
+		for i := range values {
+			if values[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("invalid reply %q", line)
+	}
+}
@@ -0,0 +1,266 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeValue struct {
+	value   string
+	set     map[string]struct{}
+	expires time.Time
+}
+
+// fakeRedis is an in-process server implementing the commands used by [RedisStorage].
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	values   map[string]fakeValue
+	commands []string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeRedis{listener: listener, password: password, values: map[string]fakeValue{}}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	authenticated := f.password == ""
+	for {
+		request, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range request.([]any) {
+			args = append(args, string(arg.([]byte)))
+		}
+		command := strings.ToUpper(args[0])
+
+		var reply string
+		switch {
+		case command == "AUTH":
+			authenticated = args[len(args)-1] == f.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		default:
+			reply = f.execute(command, args[1:])
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) execute(command string, args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, command)
+
+	get := func(key string) (fakeValue, bool) {
+		value, ok := f.values[key]
+		if ok && !value.expires.IsZero() && time.Now().After(value.expires) {
+			delete(f.values, key)
+			return fakeValue{}, false
+		}
+		return value, ok
+	}
+
+	switch command {
+	case "PING", "SELECT":
+		return "+OK\r\n"
+	case "GET":
+		value, ok := get(args[0])
+		if !ok {
+			return "$-1\r\n"
+		}
+		return bulk(value.value)
+	case "SET":
+		value := fakeValue{value: args[1]}
+		if len(args) == 4 && strings.EqualFold(args[2], "PX") {
+			ms, _ := strconv.Atoi(args[3])
+			value.expires = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		f.values[args[0]] = value
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if _, ok := get(key); ok {
+				delete(f.values, key)
+				deleted++
+			}
+		}
+		return ":" + strconv.Itoa(deleted) + "\r\n"
+	case "SADD":
+		value, ok := get(args[0])
+		if !ok {
+			value = fakeValue{set: map[string]struct{}{}}
+		}
+		for _, member := range args[1:] {
+			value.set[member] = struct{}{}
+		}
+		f.values[args[0]] = value
+		return ":1\r\n"
+	case "PEXPIRE":
+		value, ok := get(args[0])
+		if !ok {
+			return ":0\r\n"
+		}
+		ms, _ := strconv.Atoi(args[1])
+		expires := time.Now().Add(time.Duration(ms) * time.Millisecond)
+		if len(args) == 3 {
+			switch strings.ToUpper(args[2]) {
+			case "NX":
+				if !value.expires.IsZero() {
+					return ":0\r\n"
+				}
+			case "GT":
+				if value.expires.IsZero() || !expires.After(value.expires) {
+					return ":0\r\n"
+				}
+			}
+		}
+		value.expires = expires
+		f.values[args[0]] = value
+		return ":1\r\n"
+	case "SMEMBERS":
+		value, _ := get(args[0])
+		reply := "*" + strconv.Itoa(len(value.set)) + "\r\n"
+		for member := range value.set {
+			reply += bulk(member)
+		}
+		return reply
+	default:
+		return "-ERR unknown command '" + command + "'\r\n"
+	}
+}
+
+func bulk(value string) string {
+	return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+}
+
+func TestRedisStorage(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	storage := NewRedisStorage(RedisConfig{
+		Addr:     server.listener.Addr().String(),
+		Password: "secret",
+		DB:       2,
+		PoolSize: 2,
+	})
+	t.Cleanup(func() { storage.Close() })
+
+	testStorage(t, storage)
+
+	t.Run("keys are prefixed", func(t *testing.T) {
+		require.NoError(t, storage.Set(context.Background(), "prefixed", []byte("value"), time.Minute, "tag"))
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		require.Equal(t, "value", server.values["fuego:cache:prefixed"].value)
+		require.Contains(t, server.values["fuego:cache:tag:tag"].set, "fuego:cache:prefixed")
+	})
+
+	t.Run("tags expire with their last key", func(t *testing.T) {
+		ctx := context.Background()
+		require.NoError(t, storage.Set(ctx, "long", []byte("value"), time.Hour, "expiring"))
+		require.NoError(t, storage.Set(ctx, "short", []byte("value"), time.Minute, "expiring"))
+		require.NoError(t, storage.Set(ctx, "persistent", []byte("value"), 0, "expiring"))
+
+		server.mu.Lock()
+		tag := server.values["fuego:cache:tag:expiring"]
+		persistentTag := server.values["fuego:cache:persistent-tag:expiring"]
+		server.mu.Unlock()
+		require.WithinDuration(t, time.Now().Add(time.Hour), tag.expires, time.Minute)
+		require.Len(t, tag.set, 2)
+		require.True(t, persistentTag.expires.IsZero())
+		require.Contains(t, persistentTag.set, "fuego:cache:persistent")
+
+		require.NoError(t, storage.InvalidateTags(ctx, "expiring"))
+		for _, key := range []string{"long", "short", "persistent"} {
+			_, ok, err := storage.Get(ctx, key)
+			require.NoError(t, err)
+			require.False(t, ok, key)
+		}
+	})
+
+	t.Run("cache shared between servers", func(t *testing.T) {
+		// The fake server is closed after the test: its storage must not be used by the other tests.
+		storages.mu.Lock()
+		registered := storages.list
+		storages.mu.Unlock()
+		t.Cleanup(func() {
+			storages.mu.Lock()
+			storages.list = registered
+			storages.mu.Unlock()
+		})
+
+		newHandler := func(name string) http.Handler {
+			return New(Config{Storage: storage})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(name))
+			}))
+		}
+		newHandler("first").ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/shared", nil))
+
+		w := httptest.NewRecorder()
+		newHandler("second").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/shared", nil))
+		require.Equal(t, "hit", w.Header().Get("Cache"))
+		require.Equal(t, "first", w.Body.String())
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		storage := NewRedisStorage(RedisConfig{Addr: server.listener.Addr().String(), Password: "wrong"})
+		_, _, err := storage.Get(context.Background(), "key")
+		var replyErr redisError
+		require.ErrorAs(t, err, &replyErr)
+		require.Contains(t, err.Error(), "WRONGPASS")
+	})
+
+	t.Run("server unavailable", func(t *testing.T) {
+		storage := NewRedisStorage(RedisConfig{Addr: "127.0.0.1:1", Timeout: 100 * time.Millisecond})
+		_, _, err := storage.Get(context.Background(), "key")
+		require.Error(t, err)
+		var replyErr redisError
+		require.False(t, errors.As(err, &replyErr))
+	})
+}
+
+func TestReadReply(t *testing.T) {
+	reply, err := readReply(bufio.NewReader(strings.NewReader("*4\r\n+OK\r\n:42\r\n$-1\r\n$5\r\nhe\r\nl\r\n")))
+	require.NoError(t, err)
+	require.Equal(t, []any{"OK", int64(42), nil, []byte("he\r\nl")}, reply)
+
+	_, err = readReply(bufio.NewReader(strings.NewReader("?\r\n")))
+	require.Error(t, err)
+}
@@ -0,0 +1,21 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Storage stores the cached responses.
+// A storage shared by several servers (ex: [RedisStorage]) shares their cache,
+// so a response cached by a server is used by the others, and invalidating it purges it for all of them.
+type Storage interface {
+	// Get returns the value of the key, or false if it is absent or expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value of the key for the ttl, or without expiration if the ttl is 0.
+	// The tags are used to delete several keys at once with InvalidateTags.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags ...string) error
+	// Delete deletes the keys. Absent keys are ignored.
+	Delete(ctx context.Context, keys ...string) error
+	// InvalidateTags deletes the keys tagged with any of the tags.
+	InvalidateTags(ctx context.Context, tags ...string) error
+}
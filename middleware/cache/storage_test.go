@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testStorage checks the behavior common to all the storages.
+func testStorage(t *testing.T, storage Storage) {
+	ctx := context.Background()
+
+	t.Run("get and set", func(t *testing.T) {
+		_, ok, err := storage.Get(ctx, "absent")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.NoError(t, storage.Set(ctx, "key", []byte("value\r\n\x00"), 0))
+		value, ok, err := storage.Get(ctx, "key")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, []byte("value\r\n\x00"), value)
+
+		require.NoError(t, storage.Set(ctx, "key", []byte("replaced"), 0))
+		value, _, _ = storage.Get(ctx, "key")
+		require.Equal(t, []byte("replaced"), value)
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		require.NoError(t, storage.Set(ctx, "short", []byte("value"), 50*time.Millisecond))
+		require.NoError(t, storage.Set(ctx, "long", []byte("value"), time.Minute))
+
+		time.Sleep(60 * time.Millisecond)
+		_, ok, err := storage.Get(ctx, "short")
+		require.NoError(t, err)
+		require.False(t, ok)
+		_, ok, _ = storage.Get(ctx, "long")
+		require.True(t, ok)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, storage.Set(ctx, "a", []byte("a"), 0))
+		require.NoError(t, storage.Set(ctx, "b", []byte("b"), 0))
+		require.NoError(t, storage.Delete(ctx, "a", "b", "absent"))
+
+		_, ok, _ := storage.Get(ctx, "a")
+		require.False(t, ok)
+		_, ok, _ = storage.Get(ctx, "b")
+		require.False(t, ok)
+	})
+
+	t.Run("invalidate tags", func(t *testing.T) {
+		require.NoError(t, storage.Set(ctx, "recipe-1", []byte("1"), 0, "recipes", "recipe:1"))
+		require.NoError(t, storage.Set(ctx, "recipe-2", []byte("2"), 0, "recipes", "recipe:2"))
+		require.NoError(t, storage.Set(ctx, "ingredients", []byte("i"), 0, "ingredients"))
+
+		require.NoError(t, storage.InvalidateTags(ctx, "recipe:1", "absent"))
+		_, ok, _ := storage.Get(ctx, "recipe-1")
+		require.False(t, ok)
+		_, ok, _ = storage.Get(ctx, "recipe-2")
+		require.True(t, ok)
+
+		require.NoError(t, storage.InvalidateTags(ctx, "recipes"))
+		_, ok, _ = storage.Get(ctx, "recipe-2")
+		require.False(t, ok)
+		_, ok, _ = storage.Get(ctx, "ingredients")
+		require.True(t, ok)
+
+		require.NoError(t, storage.Set(ctx, "recipe-2", []byte("2"), 0))
+		require.NoError(t, storage.InvalidateTags(ctx, "recipes"))
+		_, ok, _ = storage.Get(ctx, "recipe-2")
+		require.True(t, ok, "tags are not kept after invalidation")
+	})
+}
+
+func TestTTLCache(t *testing.T) {
+	testStorage(t, NewInMemoryCache(time.Minute, 100))
+
+	t.Run("evicted keys are untagged", func(t *testing.T) {
+		storage := NewInMemoryCache(time.Minute, 1)
+		require.NoError(t, storage.Set(context.Background(), "a", []byte("a"), 0, "tag"))
+		require.NoError(t, storage.Set(context.Background(), "b", []byte("b"), 0, "other"))
+		require.Empty(t, storage.tags["tag"])
+	})
+}
+
+func TestFileStorage(t *testing.T) {
+	storage, err := NewFileStorage(t.TempDir())
+	require.NoError(t, err)
+	testStorage(t, storage)
+
+	t.Run("expired entries are swept", func(t *testing.T) {
+		dir := t.TempDir()
+		storage, err := NewFileStorage(dir)
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		require.NoError(t, storage.Set(ctx, "expired", []byte("value"), time.Millisecond, "swept"))
+		require.NoError(t, storage.Set(ctx, "kept", []byte("value"), time.Minute, "kept"))
+		time.Sleep(5 * time.Millisecond)
+		require.NoError(t, storage.Set(ctx, "other", []byte("value"), time.Minute))
+		require.FileExists(t, storage.entryPath("expired"), "swept at most once per minute")
+
+		storage.nextSweep = time.Time{}
+		require.NoError(t, storage.Set(ctx, "other", []byte("value"), time.Minute))
+		require.NoFileExists(t, storage.entryPath("expired"))
+		require.NoDirExists(t, storage.tagPath("swept"))
+		require.FileExists(t, storage.entryPath("kept"))
+		require.FileExists(t, filepath.Join(storage.tagPath("kept"), hash("kept")))
+	})
+}
@@ -1,8 +1,11 @@
 package cache
 
 import (
+	"bytes"
 	"io"
 	"net/http"
+	"slices"
+	"time"
 )
 
 // MultiHTTPWriter is a http.ResponseWriter that writes the response to multiple writers
@@ -27,3 +30,119 @@ func (m *MultiHTTPWriter) WriteHeader(statusCode int) {
 	m.status = statusCode
 	m.ResponseWriter.WriteHeader(statusCode)
 }
+
+// responseRecorder buffers the response to store it in the cache, if it can be cached.
+// Otherwise, the response is sent as is.
+type responseRecorder struct {
+	http.ResponseWriter
+	before      http.Header                                           // headers set before the handler
+	ttl         func(status int, header http.Header) (lifetime, bool) // returns whether the response can be cached, and for how long
+	holdErrors  bool                                                  // whether 5xx responses are discarded, to send a stale response instead
+	wroteHeader bool
+	status      int
+	lifetime    lifetime
+	body        bytes.Buffer
+	passThrough bool
+	failed      bool // whether the response was a discarded 5xx
+}
+
+var _ http.ResponseWriter = &responseRecorder{}
+
+func (m *responseRecorder) WriteHeader(statusCode int) {
+	if m.wroteHeader {
+		return
+	}
+	m.wroteHeader = true
+	m.status = statusCode
+
+	if m.holdErrors && statusCode >= http.StatusInternalServerError {
+		m.failed = true
+		return
+	}
+
+	lifetime, ok := m.ttl(statusCode, m.Header())
+	if !ok {
+		m.passThrough = true
+		m.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	m.lifetime = lifetime
+}
+
+func (m *responseRecorder) Write(p []byte) (int, error) {
+	if !m.wroteHeader {
+		m.WriteHeader(http.StatusOK)
+	}
+	if m.failed {
+		return len(p), nil
+	}
+	if m.passThrough {
+		return m.ResponseWriter.Write(p)
+	}
+	return m.body.Write(p)
+}
+
+// Flush sends the response written so far, unless it is buffered to be cached.
+func (m *responseRecorder) Flush() {
+	if !m.wroteHeader || !m.passThrough {
+		return
+	}
+	_ = http.NewResponseController(m.ResponseWriter).Flush()
+}
+
+func (m *responseRecorder) Unwrap() http.ResponseWriter {
+	return m.ResponseWriter
+}
+
+// buffering reports whether the response is buffered, and must be sent after the handler.
+func (m *responseRecorder) buffering() bool {
+	if !m.wroteHeader {
+		m.WriteHeader(http.StatusOK)
+	}
+	return !m.passThrough && !m.failed
+}
+
+// entry builds the cached response from the buffered one.
+func (m *responseRecorder) entry() entry {
+	header := http.Header{}
+	for name, values := range m.Header() {
+		if excludedHeaders[name] {
+			continue
+		}
+		// Headers set by the outer middlewares are set again for each request.
+		if before, ok := m.before[name]; ok && slices.Equal(before, values) && name != "Vary" {
+			continue
+		}
+		header[name] = slices.Clone(values)
+	}
+	if header.Get("ETag") == "" {
+		header.Set("ETag", computeETag(m.body.Bytes()))
+	}
+
+	now := time.Now()
+	expires := now.Add(m.lifetime.fresh)
+	return entry{
+		Status:               m.status,
+		Header:               header,
+		Body:                 bytes.Clone(m.body.Bytes()),
+		Stored:               now,
+		Expires:              expires,
+		StaleWhileRevalidate: expires.Add(m.lifetime.staleWhileRevalidate),
+		StaleIfError:         expires.Add(m.lifetime.staleIfError),
+	}
+}
+
+// discardWriter is a http.ResponseWriter discarding the response, for the requests made by the cache itself.
+type discardWriter struct {
+	header http.Header
+}
+
+func (d *discardWriter) Header() http.Header {
+	return d.header
+}
+
+func (d *discardWriter) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+func (d *discardWriter) WriteHeader(int) {}
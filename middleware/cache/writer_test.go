@@ -2,8 +2,12 @@ package cache
 
 import (
 	"bytes"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestWriter(t *testing.T) {
@@ -53,3 +57,48 @@ func TestWriter_WriteHeader(t *testing.T) {
 		t.Errorf("Expected %d, got %d", 204, m.status)
 	}
 }
+
+func TestResponseRecorder(t *testing.T) {
+	newRecorder := func(w http.ResponseWriter, cacheable bool) *responseRecorder {
+		return &responseRecorder{
+			ResponseWriter: w,
+			before:         w.Header().Clone(),
+			ttl: func(int, http.Header) (lifetime, bool) {
+				return lifetime{fresh: time.Minute}, cacheable
+			},
+		}
+	}
+
+	t.Run("buffers cacheable responses", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		w.Header().Set("X-Outer", "outer")
+		m := newRecorder(w, true)
+		m.Header().Set("X-Inner", "inner")
+		_, _ = m.Write([]byte("hello"))
+		m.Flush()
+
+		require.False(t, w.Flushed)
+		require.Empty(t, w.Body.String())
+		require.True(t, m.buffering())
+
+		cached := m.entry()
+		require.Equal(t, http.StatusOK, cached.Status)
+		require.Equal(t, "hello", string(cached.Body))
+		require.Equal(t, "inner", cached.Header.Get("X-Inner"))
+		require.Empty(t, cached.Header.Get("X-Outer"))
+		require.Equal(t, computeETag([]byte("hello")), cached.Header.Get("ETag"))
+	})
+
+	t.Run("sends other responses as is", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		m := newRecorder(w, false)
+		m.WriteHeader(http.StatusAccepted)
+		_, _ = m.Write([]byte("hello"))
+		m.Flush()
+
+		require.True(t, w.Flushed)
+		require.Equal(t, http.StatusAccepted, w.Code)
+		require.Equal(t, "hello", w.Body.String())
+		require.False(t, m.buffering())
+	})
+}
@@ -0,0 +1,140 @@
+// Package compress compresses the responses with the encoding negotiated from the Accept-Encoding header.
+package compress
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/go-fuego/fuego/internal/httpheader"
+)
+
+// Encodings supported by the middleware.
+const (
+	Brotli  = "br"
+	Gzip    = "gzip"
+	Deflate = "deflate"
+)
+
+type Config struct {
+	Encodings        []string // Encodings offered, by order of preference when the client accepts several equally. Defaults to br, gzip and deflate.
+	MinSize          int      // Responses smaller than MinSize bytes are not compressed, unless streamed with Flush. Defaults to 1024.
+	SkipContentTypes []string // Media types not compressed, as already compressed. "type/*" matches all the subtypes. Defaults to [DefaultSkipContentTypes].
+	GzipLevel        int      // Level of gzip and deflate, see [compress/flate]. Defaults to [gzip.DefaultCompression].
+	BrotliLevel      int      // Level of brotli, from 0 to 11. Defaults to 4, suited to dynamic content.
+}
+
+// DefaultSkipContentTypes are media types already compressed.
+var DefaultSkipContentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/*", "audio/*",
+	"font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/x-brotli", "application/zstd",
+	"application/pdf", "application/octet-stream",
+}
+
+// encoder is implemented by the writers of all the encodings.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// New compresses the responses with the best encoding accepted by the client, among gzip, deflate and brotli.
+// Small responses, responses with an already compressed media type and responses already encoded are sent as is.
+// The responses that may be compressed have the Vary: Accept-Encoding header, so caches store each encoding separately.
+// Strong ETags of compressed responses are made weak, as the compressed content differs from the original one.
+//
+// To cache the compressed responses, use the cache middleware outside of this one, so each encoding is cached separately:
+//
+//	fuego.Use(s, compress.New(compress.Config{}))
+//	fuego.Use(s, cache.New())
+func New(config Config) func(http.Handler) http.Handler {
+	if len(config.Encodings) == 0 {
+		config.Encodings = []string{Brotli, Gzip, Deflate}
+	}
+	if config.MinSize == 0 {
+		config.MinSize = 1024
+	}
+	if config.SkipContentTypes == nil {
+		config.SkipContentTypes = DefaultSkipContentTypes
+	}
+	if config.GzipLevel == 0 {
+		config.GzipLevel = gzip.DefaultCompression
+	}
+	if config.BrotliLevel == 0 {
+		config.BrotliLevel = 4
+	}
+
+	pools := make(map[string]*sync.Pool, len(config.Encodings))
+	for _, encoding := range config.Encodings {
+		pools[encoding] = newPool(encoding, config)
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				config:         &config,
+				encoding:       httpheader.NegotiateEncoding(r.Header.Get("Accept-Encoding"), config.Encodings),
+			}
+			cw.pool = pools[cw.encoding]
+
+			// Not deferred: after a panic, the status must be left unsent for the recovery middleware.
+			h.ServeHTTP(cw, r)
+			cw.close()
+		})
+	}
+}
+
+func newPool(encoding string, config Config) *sync.Pool {
+	var newEncoder func() encoder
+	switch encoding {
+	case Gzip:
+		if _, err := gzip.NewWriterLevel(nil, config.GzipLevel); err != nil {
+			panic("compress: " + err.Error())
+		}
+		newEncoder = func() encoder {
+			w, _ := gzip.NewWriterLevel(nil, config.GzipLevel)
+			return w
+		}
+	case Deflate:
+		if _, err := flate.NewWriter(nil, config.GzipLevel); err != nil {
+			panic("compress: " + err.Error())
+		}
+		newEncoder = func() encoder {
+			w, _ := flate.NewWriter(nil, config.GzipLevel)
+			return w
+		}
+	case Brotli:
+		newEncoder = func() encoder {
+			return brotli.NewWriterLevel(nil, config.BrotliLevel)
+		}
+	default:
+		panic("compress: unsupported encoding " + encoding)
+	}
+	return &sync.Pool{New: func() any { return newEncoder() }}
+}
+
+// skipped reports whether the media type is in the list, as is or with a "type/*" pattern.
+func skipped(contentType string, skip []string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	return slices.ContainsFunc(skip, func(pattern string) bool {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			return strings.HasPrefix(mediaType, prefix)
+		}
+		return mediaType == pattern
+	})
+}
@@ -0,0 +1,211 @@
+package compress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+	"github.com/go-fuego/fuego/middleware/cache"
+)
+
+var large = strings.Repeat(`{"name":"pizza","ingredients":["tomato","mozzarella"]},`, 100)
+
+func decode(t *testing.T, encoding string, body []byte) string {
+	t.Helper()
+	var reader io.Reader
+	switch encoding {
+	case Gzip:
+		gzipReader, err := gzip.NewReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		reader = gzipReader
+	case Deflate:
+		reader = flate.NewReader(bytes.NewReader(body))
+	case Brotli:
+		reader = brotli.NewReader(bytes.NewReader(body))
+	default:
+		return string(body)
+	}
+	decoded, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(decoded)
+}
+
+func serve(handler http.Handler, acceptEncoding string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptEncoding != "" {
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestNew(t *testing.T) {
+	respond := func(contentType, body string) http.Handler {
+		return New(Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if contentType != "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(body[:len(body)/2]))
+			_, _ = w.Write([]byte(body[len(body)/2:]))
+		}))
+	}
+
+	t.Run("negotiated encodings", func(t *testing.T) {
+		for acceptEncoding, encoding := range map[string]string{
+			"gzip":                        Gzip,
+			"deflate, gzip;q=0.5":         Deflate,
+			"gzip, deflate, br":           Brotli,
+			"br;q=0, *":                   Gzip,
+			"gzip;q=0.1, br;q=0.2, *;q=0": Brotli,
+		} {
+			w := serve(respond("application/json", large), acceptEncoding)
+
+			require.Equal(t, http.StatusCreated, w.Code)
+			require.Equal(t, encoding, w.Header().Get("Content-Encoding"), acceptEncoding)
+			require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			require.Equal(t, `W/"v1"`, w.Header().Get("ETag"))
+			require.Less(t, w.Body.Len(), len(large))
+			require.Equal(t, large, decode(t, encoding, w.Body.Bytes()))
+		}
+	})
+
+	t.Run("no accepted encoding", func(t *testing.T) {
+		for _, acceptEncoding := range []string{"", "identity", "zstd", "gzip;q=0"} {
+			w := serve(respond("application/json", large), acceptEncoding)
+
+			require.Empty(t, w.Header().Get("Content-Encoding"))
+			require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			require.Equal(t, `"v1"`, w.Header().Get("ETag"))
+			require.Equal(t, large, w.Body.String())
+		}
+	})
+
+	t.Run("small responses", func(t *testing.T) {
+		w := serve(respond("application/json", `{"name":"pizza"}`), "gzip")
+
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		require.Equal(t, `{"name":"pizza"}`, w.Body.String())
+	})
+
+	t.Run("compressed content types", func(t *testing.T) {
+		w := serve(respond("image/png", large), "gzip")
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Empty(t, w.Header().Get("Vary"))
+
+		w = serve(respond("video/mp4", large), "gzip")
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("sniffed content type", func(t *testing.T) {
+		w := serve(respond("", "<html>"+large), "gzip")
+		require.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, Gzip, w.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("already encoded", func(t *testing.T) {
+		handler := New(Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", Gzip)
+			_, _ = w.Write([]byte(large))
+		}))
+		w := serve(handler, "br")
+		require.Equal(t, Gzip, w.Header().Get("Content-Encoding"))
+		require.Equal(t, large, w.Body.String())
+	})
+
+	t.Run("no content", func(t *testing.T) {
+		handler := New(Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		w := serve(handler, "gzip")
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		require.Panics(t, func() { New(Config{Encodings: []string{"zstd"}}) })
+		require.Panics(t, func() { New(Config{GzipLevel: 42}) })
+	})
+}
+
+func TestFlush(t *testing.T) {
+	handler := New(Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: first\n\n"))
+		require.NoError(t, http.NewResponseController(w).Flush())
+
+		flushed := w.(*compressWriter).ResponseWriter.(*httptest.ResponseRecorder)
+		require.True(t, flushed.Flushed)
+		require.Equal(t, Gzip, flushed.Header().Get("Content-Encoding"), "streams are compressed whatever their size")
+		require.Equal(t, "data: first\n\n", decodePartial(t, flushed.Body.Bytes()))
+
+		_, _ = w.Write([]byte("data: second\n\n"))
+	}))
+
+	w := serve(handler, "gzip")
+	require.Equal(t, "data: first\n\ndata: second\n\n", decode(t, Gzip, w.Body.Bytes()))
+}
+
+// decodePartial decodes a gzip stream that is not finished yet.
+func decodePartial(t *testing.T, body []byte) string {
+	t.Helper()
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	require.NoError(t, err)
+	decoded, err := io.ReadAll(reader)
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	return string(decoded)
+}
+
+func TestWithCache(t *testing.T) {
+	s := fuego.NewServer()
+	fuego.Use(s, New(Config{}))
+	fuego.Use(s, cache.New())
+	calls := 0
+	fuego.Get(s, "/recipes", func(c *fuego.ContextNoBody) (string, error) {
+		calls++
+		return large, nil
+	})
+
+	get := func(acceptEncoding string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+		r.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	for _, encoding := range []string{Gzip, Brotli, ""} {
+		w := get(encoding)
+		require.Equal(t, "miss", w.Header().Get("Cache"))
+		require.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+	}
+	require.Equal(t, 3, calls)
+
+	for _, encoding := range []string{Gzip, Brotli, ""} {
+		w := get(encoding)
+		require.Equal(t, "hit", w.Header().Get("Cache"))
+		require.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+		require.Equal(t, large, decode(t, encoding, w.Body.Bytes()))
+	}
+	require.Equal(t, 3, calls)
+}
+
+func TestSkipped(t *testing.T) {
+	require.True(t, skipped("image/PNG", DefaultSkipContentTypes))
+	require.True(t, skipped("audio/ogg; codecs=opus", DefaultSkipContentTypes))
+	require.False(t, skipped("image/svg+xml", DefaultSkipContentTypes))
+	require.False(t, skipped("application/json; charset=utf-8", DefaultSkipContentTypes))
+}
@@ -0,0 +1,138 @@
+package compress
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-fuego/fuego/internal/httpheader"
+)
+
+// compressWriter holds the beginning of the response until it is large enough to be compressed.
+type compressWriter struct {
+	http.ResponseWriter
+	config   *Config
+	encoding string     // negotiated encoding, "" if the client accepts none
+	pool     *sync.Pool // encoders of the negotiated encoding
+
+	wroteHeader bool // whether the handler wrote the status
+	status      int
+	decided     bool    // whether the status was sent, compressed or not
+	encoder     encoder // set if the response is compressed
+	buf         []byte  // beginning of the response, until decided
+}
+
+var _ http.ResponseWriter = &compressWriter{}
+
+func (c *compressWriter) WriteHeader(statusCode int) {
+	if c.wroteHeader {
+		return
+	}
+	// Informational responses are followed by the final response.
+	if statusCode < http.StatusOK {
+		c.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	c.wroteHeader = true
+	c.status = statusCode
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		c.decide(false)
+	}
+}
+
+func (c *compressWriter) Write(p []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if c.decided {
+		if c.encoder != nil {
+			return c.encoder.Write(p)
+		}
+		return c.ResponseWriter.Write(p)
+	}
+
+	c.buf = append(c.buf, p...)
+	if len(c.buf) >= c.config.MinSize {
+		if err := c.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends the response written so far. A streamed response is compressed whatever its size.
+func (c *compressWriter) Flush() {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+	if !c.decided {
+		if err := c.decide(true); err != nil {
+			return
+		}
+	}
+	if c.encoder != nil {
+		if err := c.encoder.Flush(); err != nil {
+			return
+		}
+	}
+	_ = http.NewResponseController(c.ResponseWriter).Flush()
+}
+
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// decide sends the status, compressing the response if large enough and if it can be, then the buffered content.
+func (c *compressWriter) decide(largeEnough bool) error {
+	c.decided = true
+	header := c.Header()
+	if header.Get("Content-Type") == "" && len(c.buf) > 0 {
+		// Sniffed by net/http otherwise, but from the compressed content.
+		header.Set("Content-Type", http.DetectContentType(c.buf))
+	}
+
+	compressible := header.Get("Content-Encoding") == "" &&
+		c.status != http.StatusNoContent && c.status != http.StatusNotModified && c.status != http.StatusPartialContent &&
+		!skipped(header.Get("Content-Type"), c.config.SkipContentTypes)
+	if compressible {
+		httpheader.AddVary(header, "Accept-Encoding")
+	}
+
+	if compressible && largeEnough && c.encoding != "" {
+		header.Set("Content-Encoding", c.encoding)
+		header.Del("Content-Length")
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		c.encoder = c.pool.Get().(encoder)
+		c.encoder.Reset(c.ResponseWriter)
+	}
+
+	c.ResponseWriter.WriteHeader(c.status)
+	if len(c.buf) == 0 {
+		return nil
+	}
+	buf := c.buf
+	c.buf = nil
+	if c.encoder != nil {
+		_, err := c.encoder.Write(buf)
+		return err
+	}
+	_, err := c.ResponseWriter.Write(buf)
+	return err
+}
+
+// close sends the rest of the response once the handler returned.
+func (c *compressWriter) close() {
+	if !c.wroteHeader {
+		return
+	}
+	if !c.decided {
+		_ = c.decide(false)
+	}
+	if c.encoder != nil {
+		_ = c.encoder.Close()
+		c.pool.Put(c.encoder)
+		c.encoder = nil
+	}
+}
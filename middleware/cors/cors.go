@@ -0,0 +1,142 @@
+// Package cors implements Cross-Origin Resource Sharing, see https://fetch.spec.whatwg.org/#http-cors-protocol
+package cors
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-fuego/fuego"
+	"github.com/go-fuego/fuego/internal/httpheader"
+)
+
+type Config struct {
+	AllowOrigins     []string                 // Origins allowed to make cross-origin requests, ex: https://example.com. "*" allows all origins. Defaults to "*".
+	AllowOriginFunc  func(origin string) bool // Allows the origins for which it returns true, in addition to AllowOrigins.
+	AllowMethods     []string                 // Defaults to the methods registered for the path (see [Use]), or GET, HEAD and POST.
+	AllowHeaders     []string                 // Request headers allowed in cross-origin requests. Defaults to the headers requested by the preflight request.
+	ExposeHeaders    []string                 // Response headers readable by the browser, in addition to the CORS-safelisted ones.
+	AllowCredentials bool                     // Allows cookies and the Authorization header. The origin is then sent instead of "*".
+	MaxAge           time.Duration            // Duration during which the browser caches the preflight response. Not sent if 0.
+	PreflightStatus  int                      // Status of the preflight responses. Defaults to 204 No Content.
+}
+
+var defaultMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+type contextKey string
+
+const contextKeyHandled contextKey = "corsHandled"
+
+// Use answers the cross-origin requests to the routes registered afterwards on the server or the group,
+// including the preflight requests: the allowed methods are the ones registered for the requested path.
+// Call it on a group to use a different policy for its routes.
+// It must be called after the middlewares that may reject requests (ex: authentication) are registered with [fuego.Use],
+// so their responses also have the CORS headers, and the preflight requests are answered before them.
+// Example:
+//
+//	cors.Use(s, cors.Config{AllowOrigins: []string{"https://example.com"}})
+//
+//	admin := fuego.Group(s, "/admin")
+//	cors.Use(admin, cors.Config{AllowOrigins: []string{"https://admin.example.com"}, AllowCredentials: true})
+func Use(s *fuego.Server, config Config) {
+	fuego.Use(s, New(config))
+	fuego.HandleOptions(s)
+}
+
+// New returns a CORS middleware. Prefer [Use] with a Fuego server,
+// so the preflight requests reach the middleware and are answered with the registered methods.
+func New(config Config) func(http.Handler) http.Handler {
+	if len(config.AllowOrigins) == 0 && config.AllowOriginFunc == nil {
+		config.AllowOrigins = []string{"*"}
+	}
+	if config.PreflightStatus == 0 {
+		config.PreflightStatus = http.StatusNoContent
+	}
+	allowAllOrigins := slices.Contains(config.AllowOrigins, "*")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+
+	allowOrigin := func(origin string) (string, bool) {
+		switch {
+		case allowAllOrigins && !config.AllowCredentials:
+			return "*", true
+		case allowAllOrigins, slices.Contains(config.AllowOrigins, origin), config.AllowOriginFunc != nil && config.AllowOriginFunc(origin):
+			return origin, true
+		default:
+			return "", false
+		}
+	}
+	// The response depends on the origin, unless all origins receive "*".
+	varyOrigin := !allowAllOrigins || config.AllowCredentials
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The outermost policy wins, ex: the one of a group over the one of the server.
+			if r.Context().Value(contextKeyHandled) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r = r.WithContext(context.WithValue(r.Context(), contextKeyHandled, true))
+
+			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if preflight {
+				httpheader.AddVary(w.Header(), "Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers")
+			} else if varyOrigin {
+				httpheader.AddVary(w.Header(), "Origin")
+			}
+
+			allowedOrigin, ok := allowOrigin(origin)
+			if origin == "" || !ok {
+				if preflight {
+					w.WriteHeader(config.PreflightStatus)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
+			if config.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !preflight {
+				if exposeHeaders != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(allowedMethods(r, config), ", "))
+			allowHeaders := strings.Join(config.AllowHeaders, ", ")
+			if len(config.AllowHeaders) == 0 {
+				allowHeaders = r.Header.Get("Access-Control-Request-Headers")
+			}
+			if allowHeaders != "" {
+				w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+			}
+			if config.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+			}
+			w.WriteHeader(config.PreflightStatus)
+		})
+	}
+}
+
+// allowedMethods returns the methods allowed for the path of the preflight request.
+func allowedMethods(r *http.Request, config Config) []string {
+	if len(config.AllowMethods) > 0 {
+		return config.AllowMethods
+	}
+	if registered := fuego.AllowedMethods(r.Context()); len(registered) > 0 {
+		return slices.DeleteFunc(registered, func(method string) bool {
+			return method == http.MethodOptions
+		})
+	}
+	return defaultMethods
+}
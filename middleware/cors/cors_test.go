@@ -0,0 +1,170 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+func preflight(s *fuego.Server, path, origin, method string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodOptions, path, nil)
+	r.Header.Set("Origin", origin)
+	r.Header.Set("Access-Control-Request-Method", method)
+	r.Header.Set("Access-Control-Request-Headers", "Content-Type, X-Custom")
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+	return w
+}
+
+func get(s *fuego.Server, path, origin string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+	return w
+}
+
+func newServer() *fuego.Server {
+	s := fuego.NewServer()
+	Use(s, Config{
+		AllowOrigins:  []string{"https://example.com"},
+		ExposeHeaders: []string{"X-Total-Count"},
+		MaxAge:        time.Hour,
+	})
+
+	fuego.Get(s, "/recipes", func(c *fuego.ContextNoBody) (string, error) { return "recipes", nil })
+	fuego.Post(s, "/recipes", func(c *fuego.ContextNoBody) (string, error) { return "created", nil })
+	fuego.Get(s, "/recipes/{id}", func(c *fuego.ContextNoBody) (string, error) { return "recipe", nil })
+	fuego.Delete(s, "/recipes/{id}", func(c *fuego.ContextNoBody) (string, error) { return "deleted", nil })
+
+	admin := fuego.Group(s, "/admin")
+	Use(admin, Config{
+		AllowOrigins:     []string{"https://admin.example.com"},
+		AllowHeaders:     []string{"Authorization"},
+		AllowCredentials: true,
+	})
+	fuego.All(admin, "/recipes", func(c *fuego.ContextNoBody) (string, error) { return "admin", nil })
+	return s
+}
+
+func TestCORS(t *testing.T) {
+	s := newServer()
+
+	t.Run("preflight with the registered methods", func(t *testing.T) {
+		w := preflight(s, "/recipes/1", "https://example.com", http.MethodDelete)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "GET, HEAD, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Content-Type, X-Custom", w.Header().Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "3600", w.Header().Get("Access-Control-Max-Age"))
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+		require.Equal(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, w.Header().Values("Vary"))
+
+		w = preflight(s, "/recipes", "https://example.com", http.MethodPost)
+		require.Equal(t, "GET, HEAD, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("preflight from a forbidden origin", func(t *testing.T) {
+		w := preflight(s, "/recipes", "https://evil.com", http.MethodPost)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("actual request", func(t *testing.T) {
+		w := get(s, "/recipes", "https://example.com")
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "recipes", w.Body.String())
+		require.Equal(t, "https://example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "X-Total-Count", w.Header().Get("Access-Control-Expose-Headers"))
+		require.Contains(t, w.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("same-origin request", func(t *testing.T) {
+		w := get(s, "/recipes", "")
+
+		require.Equal(t, "recipes", w.Body.String())
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		require.Contains(t, w.Header().Values("Vary"), "Origin", "cached responses must not be reused for cross-origin requests")
+	})
+
+	t.Run("OPTIONS without preflight", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodOptions, "/recipes/1", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "GET, HEAD, DELETE, OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("group policy", func(t *testing.T) {
+		w := preflight(s, "/admin/recipes", "https://admin.example.com", http.MethodPut)
+
+		require.Equal(t, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		require.Equal(t, "GET, HEAD, POST, PUT, PATCH, DELETE", w.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+
+		w = get(s, "/admin/recipes", "https://admin.example.com")
+		require.Equal(t, "admin", w.Body.String())
+		require.Equal(t, "https://admin.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, w.Header().Get("Access-Control-Expose-Headers"), "the policy of the server is not applied")
+		require.Equal(t, []string{"Origin"}, w.Header().Values("Vary"))
+
+		w = get(s, "/admin/recipes", "https://example.com")
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func TestNew(t *testing.T) {
+	handler := New(Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	t.Run("all origins", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Origin", "https://example.com")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusTeapot, w.Code)
+		require.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Empty(t, w.Header().Values("Vary"))
+	})
+
+	t.Run("preflight without registered methods", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodOptions, "/", nil)
+		r.Header.Set("Origin", "https://example.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "GET, HEAD, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("origin func with credentials", func(t *testing.T) {
+		handler := New(Config{
+			AllowOriginFunc:  func(origin string) bool { return origin == "https://a.example.com" },
+			AllowCredentials: true,
+		})(http.NotFoundHandler())
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Origin", "https://a.example.com")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		require.Equal(t, "https://a.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+}
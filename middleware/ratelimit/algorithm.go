@@ -0,0 +1,153 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// State is the state of the limiter of a client, kept in the [Store] between its requests.
+// Its meaning depends on the [Algorithm].
+type State struct {
+	Count    float64   // Tokens left (token bucket), or requests in the current window (sliding window).
+	Previous float64   // Requests in the previous window (sliding window).
+	Time     time.Time // Last refill (token bucket), or start of the current window (sliding window).
+}
+
+// Result is the decision of an [Algorithm] for a request.
+type Result struct {
+	Allowed    bool
+	Limit      int           // Maximum number of requests in a burst or a window.
+	Remaining  int           // Requests left before being limited.
+	Reset      time.Duration // Time before the quota is fully available again.
+	RetryAfter time.Duration // Time before the next request is allowed, if not allowed.
+}
+
+// Algorithm decides whether a request is allowed, from the state of the client.
+// The algorithms of this package are [TokenBucketLimit] and [SlidingWindowLimit].
+type Algorithm interface {
+	// Take consumes a request from the state, and returns the new state.
+	Take(state State, now time.Time) (State, Result)
+	// TTL is the duration after which an unchanged state is equivalent to a new one, and can be forgotten.
+	TTL() time.Duration
+}
+
+// TokenBucketLimit is the [Algorithm] returned by [TokenBucket].
+type TokenBucketLimit struct {
+	Burst     int     // Maximum number of tokens in the bucket.
+	PerSecond float64 // Tokens added to the bucket per second.
+}
+
+// TokenBucket allows bursts of requests, then a constant rate: the bucket holds up to burst tokens,
+// refilled with rate tokens per period, and each request consumes a token.
+// Example, 10 requests per second with bursts of 20 requests:
+//
+//	ratelimit.TokenBucket(10, time.Second, 20)
+func TokenBucket(rate int, per time.Duration, burst int) Algorithm {
+	if rate <= 0 || per <= 0 || burst <= 0 {
+		panic("ratelimit: rate, period and burst must be positive")
+	}
+	return TokenBucketLimit{Burst: burst, PerSecond: float64(rate) / per.Seconds()}
+}
+
+func (b TokenBucketLimit) Take(state State, now time.Time) (State, Result) {
+	burst := float64(b.Burst)
+	tokens := burst
+	if !state.Time.IsZero() {
+		elapsed := max(now.Sub(state.Time).Seconds(), 0)
+		tokens = min(burst, state.Count+elapsed*b.PerSecond)
+	}
+
+	result := Result{Limit: b.Burst}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - tokens) / b.PerSecond)
+	}
+	result.Remaining = int(tokens)
+	result.Reset = secondsToDuration((burst - tokens) / b.PerSecond)
+
+	return State{Count: tokens, Time: now}, result
+}
+
+// TTL is the time to refill the bucket.
+func (b TokenBucketLimit) TTL() time.Duration {
+	return secondsToDuration(float64(b.Burst) / b.PerSecond)
+}
+
+// SlidingWindowLimit is the [Algorithm] returned by [SlidingWindow].
+type SlidingWindowLimit struct {
+	Limit  int           // Maximum number of requests in a window.
+	Window time.Duration // Duration of the window.
+}
+
+// SlidingWindow allows limit requests in any window of the given duration.
+// The count of the sliding window is estimated from the counts of the current and the previous fixed windows,
+// which takes a constant space per client.
+// Example, 100 requests per minute:
+//
+//	ratelimit.SlidingWindow(100, time.Minute)
+func SlidingWindow(limit int, window time.Duration) Algorithm {
+	if limit <= 0 || window <= 0 {
+		panic("ratelimit: limit and window must be positive")
+	}
+	return SlidingWindowLimit{Limit: limit, Window: window}
+}
+
+func (s SlidingWindowLimit) Take(state State, now time.Time) (State, Result) {
+	windowStart := now.Truncate(s.Window)
+	if !state.Time.Equal(windowStart) {
+		if state.Time.Equal(windowStart.Add(-s.Window)) {
+			state.Previous = state.Count
+		} else {
+			state.Previous = 0
+		}
+		state.Count = 0
+		state.Time = windowStart
+	}
+
+	elapsed := now.Sub(windowStart)
+	// Part of the previous window still in the sliding window.
+	previousWeight := 1 - elapsed.Seconds()/s.Window.Seconds()
+	estimated := state.Previous*previousWeight + state.Count
+
+	limit := float64(s.Limit)
+	result := Result{
+		Limit: s.Limit,
+		Reset: s.Window - elapsed,
+	}
+	if estimated+1 <= limit {
+		state.Count++
+		estimated++
+		result.Allowed = true
+	} else {
+		result.RetryAfter = s.retryAfter(state, elapsed)
+	}
+	result.Remaining = max(int(limit-math.Ceil(estimated)), 0)
+	if state.Previous > 0 {
+		result.Reset += s.Window
+	}
+
+	return state, result
+}
+
+// retryAfter returns the time before the estimated count leaves room for a request.
+func (s SlidingWindowLimit) retryAfter(state State, elapsed time.Duration) time.Duration {
+	limit := float64(s.Limit)
+	if state.Count+1 > limit || state.Previous == 0 {
+		// The requests of the current window are enough to reach the limit: wait for the next one.
+		return s.Window - elapsed
+	}
+	// Solve previous * (1 - t/window) + count + 1 <= limit for t.
+	maxWeight := (limit - state.Count - 1) / state.Previous
+	return secondsToDuration((1-maxWeight)*s.Window.Seconds()) - elapsed
+}
+
+// TTL is two windows: the count of the previous window is used in the current one.
+func (s SlidingWindowLimit) TTL() time.Duration {
+	return 2 * s.Window
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps the states of the clients. A store shared by several servers limits the requests of a client across all of them.
+type Store interface {
+	// Take consumes a request of the client identified by the key with the algorithm, at the current time of the store,
+	// and returns the decision. It must be atomic for a given key: a networked store can run the algorithm server-side
+	// in a single round trip (ex: in a Redis script), from the parameters of [TokenBucketLimit] and [SlidingWindowLimit].
+	// The state of the key can be forgotten after the TTL of the algorithm.
+	Take(ctx context.Context, key string, algorithm Algorithm) (Result, error)
+}
+
+type inMemoryEntry struct {
+	state   State
+	expires time.Time
+}
+
+// InMemoryStore is a [Store] for a single server. The expired states are removed periodically.
+type InMemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]inMemoryEntry
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+var _ Store = (*InMemoryStore)(nil)
+
+const sweepInterval = time.Minute
+
+func NewInMemoryStore() *InMemoryStore {
+	return &InMemoryStore{
+		entries: make(map[string]inMemoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (m *InMemoryStore) Take(_ context.Context, key string, algorithm Algorithm) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if now.After(m.nextSweep) {
+		m.sweep(now)
+	}
+
+	var state State
+	if entry, ok := m.entries[key]; ok && now.Before(entry.expires) {
+		state = entry.state
+	}
+	state, result := algorithm.Take(state, now)
+	m.entries[key] = inMemoryEntry{
+		state:   state,
+		expires: now.Add(algorithm.TTL()),
+	}
+	return result, nil
+}
+
+// sweep removes the expired states.
+func (m *InMemoryStore) sweep(now time.Time) {
+	for key, entry := range m.entries {
+		if !now.Before(entry.expires) {
+			delete(m.entries, key)
+		}
+	}
+	m.nextSweep = now.Add(sweepInterval)
+}
+
+// Len returns the number of states stored, including the expired ones not removed yet.
+func (m *InMemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.entries)
+}
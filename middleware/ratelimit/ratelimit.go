@@ -0,0 +1,107 @@
+// Package ratelimit limits the number of requests of each client.
+package ratelimit
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-fuego/fuego"
+)
+
+type Config struct {
+	Algorithm Algorithm                    // Defaults to 10 requests per second, with bursts of 20 requests. See [TokenBucket] and [SlidingWindow].
+	Key       func(r *http.Request) string // Identifies the client. Defaults to [KeyByIP]. Requests with an empty key are not limited.
+	Store     Store                        // Defaults to a new [InMemoryStore].
+	Prefix    string                       // Prefix of the keys in the store, to use several limiters with the same store. Defaults to "ratelimit:".
+}
+
+// New limits the number of requests of each client, identified by the key of the config.
+// The quota is sent in the RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers,
+// see https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/
+// When a client is limited, a 429 Too Many Requests error is sent, with the Retry-After header.
+// If the store fails, the request is allowed.
+// Example, 100 requests per minute per authenticated user:
+//
+//	fuego.Use(api, ratelimit.New(ratelimit.Config{
+//		Algorithm: ratelimit.SlidingWindow(100, time.Minute),
+//		Key:       ratelimit.KeyByJWTSubject,
+//	}))
+func New(config Config) func(http.Handler) http.Handler {
+	if config.Algorithm == nil {
+		config.Algorithm = TokenBucket(10, time.Second, 20)
+	}
+	if config.Key == nil {
+		config.Key = KeyByIP
+	}
+	if config.Store == nil {
+		config.Store = NewInMemoryStore()
+	}
+	if config.Prefix == "" {
+		config.Prefix = "ratelimit:"
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := config.Key(r)
+			if key == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := config.Store.Take(r.Context(), config.Prefix+key, config.Algorithm)
+			if err != nil {
+				slog.Error("ratelimit: cannot update the state, allowing the request", "key", key, "error", err)
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				fuego.SendJSONError(w, fuego.TooManyRequestsError{
+					Message: "rate limit exceeded, retry in " + strconv.Itoa(ceilSeconds(result.RetryAfter)) + "s",
+				})
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// KeyByIP identifies the clients by their IP address.
+// Behind a reverse proxy, the address is the one of the proxy: use a middleware that sets [http.Request.RemoteAddr]
+// from the X-Forwarded-For header, or a custom key.
+func KeyByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// KeyByJWTSubject identifies the clients by the subject of their token, see [fuego.TokenFromContext].
+// The requests without token are identified by their IP address.
+// To be used after the [fuego.Security.TokenToContext] middleware.
+func KeyByJWTSubject(r *http.Request) string {
+	claims, err := fuego.TokenFromContext(r.Context())
+	if err != nil {
+		return "ip:" + KeyByIP(r)
+	}
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return "ip:" + KeyByIP(r)
+	}
+	return "sub:" + subject
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
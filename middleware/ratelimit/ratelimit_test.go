@@ -0,0 +1,215 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/go-fuego/fuego"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestTokenBucket(t *testing.T) {
+	bucket := TokenBucket(1, time.Second, 3)
+
+	t.Run("burst then refill", func(t *testing.T) {
+		var state State
+		var result Result
+		for i := range 3 {
+			state, result = bucket.Take(state, start)
+			require.True(t, result.Allowed)
+			require.Equal(t, 3, result.Limit)
+			require.Equal(t, 2-i, result.Remaining)
+		}
+		require.Equal(t, 3*time.Second, result.Reset)
+
+		state, result = bucket.Take(state, start.Add(500*time.Millisecond))
+		require.False(t, result.Allowed)
+		require.Equal(t, 0, result.Remaining)
+		require.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+		_, result = bucket.Take(state, start.Add(time.Second))
+		require.True(t, result.Allowed)
+	})
+
+	t.Run("bucket is full after the TTL", func(t *testing.T) {
+		require.Equal(t, 3*time.Second, bucket.TTL())
+
+		state, _ := bucket.Take(State{}, start)
+		_, result := bucket.Take(state, start.Add(time.Hour))
+		require.Equal(t, 2, result.Remaining, "tokens are capped to the burst")
+	})
+
+	t.Run("parameters for the stores", func(t *testing.T) {
+		require.Equal(t, TokenBucketLimit{Burst: 3, PerSecond: 1}, bucket)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		require.Panics(t, func() { TokenBucket(0, time.Second, 1) })
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	window := SlidingWindow(4, time.Minute)
+
+	t.Run("limit in the current window", func(t *testing.T) {
+		var state State
+		var result Result
+		for range 4 {
+			state, result = window.Take(state, start.Add(10*time.Second))
+			require.True(t, result.Allowed)
+		}
+		require.Equal(t, 0, result.Remaining)
+		require.Equal(t, 50*time.Second, result.Reset)
+
+		_, result = window.Take(state, start.Add(20*time.Second))
+		require.False(t, result.Allowed)
+		require.Equal(t, 40*time.Second, result.RetryAfter)
+	})
+
+	t.Run("previous window is weighted", func(t *testing.T) {
+		var state State
+		for range 4 {
+			state, _ = window.Take(state, start.Add(50*time.Second))
+		}
+
+		// 25% of the next window elapsed: 75% of the previous requests are counted.
+		state, result := window.Take(state, start.Add(75*time.Second))
+		require.True(t, result.Allowed)
+		require.Equal(t, 0, result.Remaining)
+
+		_, result = window.Take(state, start.Add(75*time.Second))
+		require.False(t, result.Allowed)
+		// 3 allowed requests when the previous window weighs 50%.
+		require.Equal(t, 15*time.Second, result.RetryAfter)
+	})
+
+	t.Run("older windows are forgotten", func(t *testing.T) {
+		var state State
+		for range 4 {
+			state, _ = window.Take(state, start)
+		}
+		_, result := window.Take(state, start.Add(2*time.Minute))
+		require.True(t, result.Allowed)
+		require.Equal(t, 3, result.Remaining)
+	})
+}
+
+// counter is an [Algorithm] counting the requests, to test the stores.
+type counter struct{}
+
+func (counter) Take(state State, now time.Time) (State, Result) {
+	state.Count++
+	return state, Result{Allowed: true, Remaining: int(state.Count)}
+}
+
+func (counter) TTL() time.Duration { return time.Second }
+
+func TestInMemoryStore(t *testing.T) {
+	store := NewInMemoryStore()
+	now := start
+	store.now = func() time.Time { return now }
+
+	take := func(key string) int {
+		t.Helper()
+		result, err := store.Take(context.Background(), key, counter{})
+		require.NoError(t, err)
+		return result.Remaining
+	}
+
+	take("a")
+	require.Equal(t, 2, take("a"))
+
+	now = now.Add(time.Second)
+	require.Equal(t, 1, take("b"))
+	require.Equal(t, 1, take("a"), "expired")
+	require.Equal(t, 2, store.Len())
+
+	now = now.Add(2 * sweepInterval)
+	take("c")
+	require.Equal(t, 1, store.Len(), "expired states are removed")
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Algorithm) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestNew(t *testing.T) {
+	newServer := func(config Config) *fuego.Server {
+		s := fuego.NewServer()
+		fuego.Use(s, New(config))
+		fuego.Get(s, "/recipes", func(c *fuego.ContextNoBody) (string, error) { return "recipes", nil })
+		return s
+	}
+	get := func(s *fuego.Server, remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/recipes", nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("limits each client", func(t *testing.T) {
+		s := newServer(Config{Algorithm: TokenBucket(1, time.Minute, 2)})
+
+		w := get(s, "192.0.2.1:1234")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+		require.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+		require.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+		require.Empty(t, w.Header().Get("Retry-After"))
+
+		w = get(s, "192.0.2.1:5678")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+		w = get(s, "192.0.2.1:1234")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.Contains(t, w.Body.String(), `"error":"rate limit exceeded, retry in `)
+		require.NotEmpty(t, w.Header().Get("Retry-After"))
+		require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+
+		w = get(s, "192.0.2.2:1234")
+		require.Equal(t, http.StatusOK, w.Code, "other clients are not limited")
+	})
+
+	t.Run("custom key", func(t *testing.T) {
+		s := newServer(Config{
+			Algorithm: SlidingWindow(1, time.Minute),
+			Key:       func(r *http.Request) string { return r.Header.Get("X-API-Key") },
+		})
+
+		require.Equal(t, http.StatusOK, get(s, "192.0.2.1:1234").Code)
+		require.Equal(t, http.StatusOK, get(s, "192.0.2.1:1234").Code, "requests without key are not limited")
+		require.Empty(t, get(s, "192.0.2.1:1234").Header().Get("RateLimit-Limit"))
+	})
+
+	t.Run("allows the requests when the store fails", func(t *testing.T) {
+		s := newServer(Config{Algorithm: TokenBucket(1, time.Minute, 1), Store: failingStore{}})
+
+		require.Equal(t, http.StatusOK, get(s, "192.0.2.1:1234").Code)
+		require.Equal(t, http.StatusOK, get(s, "192.0.2.1:1234").Code)
+	})
+}
+
+func TestKeyByJWTSubject(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+	require.Equal(t, "ip:192.0.2.1", KeyByJWTSubject(r))
+
+	r = r.WithContext(fuego.WithValue(r.Context(), jwt.MapClaims{"sub": "user-1"}))
+	require.Equal(t, "sub:user-1", KeyByJWTSubject(r))
+
+	r = r.WithContext(fuego.WithValue(r.Context(), jwt.MapClaims{"role": "admin"}))
+	require.Equal(t, "ip:192.0.2.1", KeyByJWTSubject(r))
+}
@@ -0,0 +1,215 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+const defaultMultipartMaxMemory = 32 << 20 // 32 MB, same as net/http.
+
+// FileUploadLimits are the limits applied to multipart/form-data request bodies.
+// See [LimitFileUploads].
+type FileUploadLimits struct {
+	MaxFileSize int64 // Maximum size of each uploaded file, in bytes. 0 means no limit.
+	MaxFiles    int   // Maximum number of uploaded files. 0 means no limit.
+	MaxMemory   int64 // Maximum size of the files kept in memory, the rest is stored in temporary files. Defaults to 32 MB.
+}
+
+const contextKeyFileUploadLimits contextKey = "fileUploadLimits"
+
+// LimitFileUploads is a middleware that sets the limits applied to the files uploaded to a route.
+// Requests exceeding the limits are answered with 413 Request Entity Too Large
+// when the body is read with [ContextWithBody.Body] or [ContextNoBody.FormFile].
+// For example:
+//
+//	fuego.Post(s, "/avatar", uploadAvatar, fuego.LimitFileUploads(fuego.FileUploadLimits{
+//		MaxFileSize: 2 << 20, // 2 MB
+//		MaxFiles:    1,
+//	}))
+func LimitFileUploads(limits FileUploadLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), contextKeyFileUploadLimits, limits)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// parseMultipartForm parses the multipart/form-data request body, and checks the uploaded files against the route limits.
+// The limits are checked while the body is received, so a request exceeding them is rejected before being stored.
+// The form is parsed only once, so it can be called several times.
+// The files kept in temporary files are removed after the controller returns.
+func parseMultipartForm(r *http.Request, maxBodySize int64) (*multipart.Form, error) {
+	if r.MultipartForm != nil {
+		return r.MultipartForm, nil
+	}
+
+	limits, _ := r.Context().Value(contextKeyFileUploadLimits).(FileUploadLimits)
+	maxMemory := limits.MaxMemory
+	if maxMemory == 0 {
+		maxMemory = defaultMultipartMaxMemory
+	}
+	if maxBodySize != 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, maxBodySize)
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, BadRequestError{Message: "cannot parse multipart form: " + err.Error(), Err: err}
+	}
+
+	// The parts are checked by copyParts before being read by multipart.Reader.ReadForm, that stores them.
+	pipeReader, pipeWriter := io.Pipe()
+	writer := multipart.NewWriter(pipeWriter)
+	go func() {
+		_ = pipeWriter.CloseWithError(copyParts(writer, reader, limits))
+	}()
+	form, err := multipart.NewReader(pipeReader, writer.Boundary()).ReadForm(maxMemory)
+	_ = pipeReader.Close() // Stops copyParts if ReadForm failed before the end of the body.
+	if err != nil {
+		var httpError HTTPError
+		if errors.As(err, &httpError) {
+			return nil, httpError
+		}
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			return nil, HTTPError{
+				StatusCode: http.StatusRequestEntityTooLarge,
+				Message:    "request body too large, maximum is " + strconv.FormatInt(maxBytesError.Limit, 10) + " bytes",
+				Err:        err,
+			}
+		}
+		return nil, BadRequestError{Message: "cannot parse multipart form: " + err.Error(), Err: err}
+	}
+
+	r.MultipartForm = form
+	if r.Form == nil {
+		_ = r.ParseForm() // Only parses the query, as the body is multipart.
+	}
+	if r.PostForm == nil {
+		r.PostForm = url.Values{}
+	}
+	for name, values := range form.Value {
+		r.Form[name] = append(r.Form[name], values...)
+		r.PostForm[name] = append(r.PostForm[name], values...)
+	}
+
+	return form, nil
+}
+
+// copyParts copies the parts of the multipart body to the writer, and stops at the first file exceeding the limits.
+func copyParts(writer *multipart.Writer, reader *multipart.Reader, limits FileUploadLimits) error {
+	files := 0
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return writer.Close()
+		}
+		if err != nil {
+			return err
+		}
+
+		var content io.Reader = part
+		isFile := part.FileName() != ""
+		if isFile {
+			files++
+			if limits.MaxFiles != 0 && files > limits.MaxFiles {
+				return HTTPError{
+					StatusCode: http.StatusRequestEntityTooLarge,
+					Message:    "too many files uploaded, maximum is " + strconv.Itoa(limits.MaxFiles),
+				}
+			}
+			if limits.MaxFileSize != 0 {
+				content = io.LimitReader(part, limits.MaxFileSize+1)
+			}
+		}
+
+		partWriter, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return err
+		}
+		size, err := io.Copy(partWriter, content)
+		if err != nil {
+			return err
+		}
+		if isFile && limits.MaxFileSize != 0 && size > limits.MaxFileSize {
+			return HTTPError{
+				StatusCode: http.StatusRequestEntityTooLarge,
+				Message:    "file " + part.FileName() + " is too large, maximum is " + strconv.FormatInt(limits.MaxFileSize, 10) + " bytes",
+				MoreInfo: map[string]any{
+					"field": part.FormName(),
+				},
+			}
+		}
+	}
+}
+
+// removeMultipartForm removes the temporary files of the multipart form parsed by [parseMultipartForm], if any.
+// net/http only removes the ones of the form parsed on the original request, not on its copies.
+func removeMultipartForm(r *http.Request) {
+	if r.MultipartForm != nil {
+		_ = r.MultipartForm.RemoveAll()
+	}
+}
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+// DeserializeMultipart decodes a multipart/form-data request body.
+// Form values are decoded as with [DeserializeURLEncoded].
+// Uploaded files are set to the fields of type *multipart.FileHeader or []*multipart.FileHeader with the same name.
+// Example:
+//
+//	type AvatarUpload struct {
+//		Name   string                `schema:"name"`
+//		Avatar *multipart.FileHeader `schema:"avatar"`
+//	}
+func DeserializeMultipart(r *http.Request, body any, options DeserializeOptions) error {
+	form, err := parseMultipartForm(r, 0) // The body is already limited by [ContextWithBody.Body].
+	if err != nil {
+		return err
+	}
+
+	err = formDecoder(options).Decode(body, form.Value)
+	if err != nil {
+		return BadRequestError{
+			Message: "cannot decode multipart/form-data request body: " + err.Error(),
+			Err:     err,
+			MoreInfo: map[string]any{
+				"form": form.Value,
+				"help": "check that the form is valid, and that the content-type is correct",
+			},
+		}
+	}
+
+	setFormFiles(reflect.ValueOf(body).Elem(), form.File)
+
+	return nil
+}
+
+// setFormFiles sets the uploaded files to the matching fields of the struct.
+func setFormFiles(value reflect.Value, files map[string][]*multipart.FileHeader) {
+	if value.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		headers := files[fieldAlias(field, "schema")]
+		if len(headers) == 0 {
+			continue
+		}
+
+		switch field.Type {
+		case fileHeaderType:
+			value.Field(i).Set(reflect.ValueOf(headers[0]))
+		case reflect.SliceOf(fileHeaderType):
+			value.Field(i).Set(reflect.ValueOf(headers))
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package fuego
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type avatarUpload struct {
+	Name   string                  `schema:"name"`
+	Avatar *multipart.FileHeader   `schema:"avatar"`
+	Photos []*multipart.FileHeader `schema:"photos"`
+}
+
+// newMultipartRequest creates a multipart/form-data request with the given values and files (field name -> file contents).
+func newMultipartRequest(t *testing.T, path string, values map[string]string, files map[string][]string) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range values {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	for name, contents := range files {
+		for _, content := range contents {
+			part, err := writer.CreateFormFile(name, name+".txt")
+			require.NoError(t, err)
+			_, err = part.Write([]byte(content))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, writer.Close())
+
+	r := httptest.NewRequest(http.MethodPost, path, body)
+	r.Header.Set("Content-Type", writer.FormDataContentType())
+	return r
+}
+
+func TestMultipartBody(t *testing.T) {
+	s := NewServer()
+	Post(s, "/upload", func(c *ContextWithBody[avatarUpload]) (string, error) {
+		body, err := c.Body()
+		if err != nil {
+			return "", err
+		}
+
+		file, err := body.Avatar.Open()
+		if err != nil {
+			return "", err
+		}
+		defer file.Close()
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return "", err
+		}
+
+		return body.Name + ":" + string(content) + ":" + body.Photos[1].Filename, nil
+	})
+	Post(s, "/limited", func(c *ContextWithBody[avatarUpload]) (string, error) {
+		_, err := c.Body()
+		return "ok", err
+	}, LimitFileUploads(FileUploadLimits{MaxFileSize: 5, MaxFiles: 2}))
+
+	t.Run("can read values and files", func(t *testing.T) {
+		r := newMultipartRequest(t, "/upload",
+			map[string]string{"name": "John"},
+			map[string][]string{"avatar": {"avatar content"}, "photos": {"1", "2"}},
+		)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "John:avatar content:photos.txt", w.Body.String())
+	})
+
+	t.Run("files under the limits", func(t *testing.T) {
+		r := newMultipartRequest(t, "/limited", nil, map[string][]string{"photos": {"small", "small"}})
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("file too large", func(t *testing.T) {
+		r := newMultipartRequest(t, "/limited", nil, map[string][]string{"avatar": {"too large"}})
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Contains(t, w.Body.String(), "file avatar.txt is too large")
+	})
+
+	t.Run("stops reading a file too large", func(t *testing.T) {
+		writer := multipart.NewWriter(io.Discard)
+		head := "--" + writer.Boundary() + "\r\n" +
+			"Content-Disposition: form-data; name=\"avatar\"; filename=\"avatar.txt\"\r\n\r\n"
+		endless := &countingReader{}
+		r := httptest.NewRequest(http.MethodPost, "/limited", io.MultiReader(strings.NewReader(head), endless))
+		r.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Less(t, endless.read, 1<<20, "the body is not read entirely")
+	})
+
+	t.Run("too many files", func(t *testing.T) {
+		r := newMultipartRequest(t, "/limited", nil, map[string][]string{"photos": {"1", "2", "3"}})
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		require.Contains(t, w.Body.String(), "too many files")
+	})
+}
+
+// countingReader is an endless body, counting the bytes read.
+type countingReader struct {
+	read int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+	r.read += len(p)
+	return len(p), nil
+}
+
+func TestMultipartTemporaryFiles(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	s := NewServer()
+	Post(s, "/upload", func(c *ContextWithBody[avatarUpload]) (string, error) {
+		body, err := c.Body()
+		if err != nil {
+			return "", err
+		}
+		files, err := os.ReadDir(tmp)
+		require.NoError(t, err)
+		require.NotEmpty(t, files, "the file is stored on disk")
+		return body.Avatar.Filename, nil
+	}, LimitFileUploads(FileUploadLimits{MaxMemory: 1}))
+
+	r := newMultipartRequest(t, "/upload", nil, map[string][]string{"avatar": {strings.Repeat("a", 1024)}})
+	w := httptest.NewRecorder()
+
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	files, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	require.Empty(t, files, "the temporary files are removed after the controller returns")
+}
+
+func TestContext_FormFile(t *testing.T) {
+	t.Run("can read file", func(t *testing.T) {
+		r := newMultipartRequest(t, "/", nil, map[string][]string{"avatar": {"avatar content"}})
+		c := NewContext[any](httptest.NewRecorder(), r, readOptions{})
+
+		file, header, err := c.FormFile("avatar")
+		require.NoError(t, err)
+		defer file.Close()
+		require.Equal(t, "avatar.txt", header.Filename)
+
+		content, err := io.ReadAll(file)
+		require.NoError(t, err)
+		require.Equal(t, "avatar content", string(content))
+	})
+
+	t.Run("file not found", func(t *testing.T) {
+		r := newMultipartRequest(t, "/", nil, nil)
+		c := NewContext[any](httptest.NewRecorder(), r, readOptions{})
+
+		_, _, err := c.FormFile("avatar")
+		require.ErrorAs(t, err, &BadRequestError{})
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		r := newMultipartRequest(t, "/", nil, map[string][]string{"avatar": {"avatar content"}})
+		c := NewContext[any](httptest.NewRecorder(), r, readOptions{MaxBodySize: 10})
+
+		_, _, err := c.FormFile("avatar")
+		var httpError HTTPError
+		require.ErrorAs(t, err, &httpError)
+		require.Equal(t, http.StatusRequestEntityTooLarge, httpError.StatusCode)
+	})
+
+	t.Run("not a multipart request", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))
+		c := NewContext[any](httptest.NewRecorder(), r, readOptions{})
+
+		_, _, err := c.FormFile("avatar")
+		require.ErrorAs(t, err, &BadRequestError{})
+	})
+}
+
+func TestContext_MultipartReader(t *testing.T) {
+	r := newMultipartRequest(t, "/", nil, map[string][]string{"avatar": {"avatar content"}})
+	c := NewContext[any](httptest.NewRecorder(), r, readOptions{})
+
+	reader, err := c.MultipartReader()
+	require.NoError(t, err)
+
+	part, err := reader.NextPart()
+	require.NoError(t, err)
+	require.Equal(t, "avatar", part.FormName())
+
+	content, err := io.ReadAll(part)
+	require.NoError(t, err)
+	require.Equal(t, "avatar content", string(content))
+}
+
+func TestMultipartOpenAPI(t *testing.T) {
+	s := NewServer()
+	route := Post(s, "/upload", func(c *ContextWithBody[avatarUpload]) (string, error) {
+		return "", nil
+	})
+
+	schema := route.operation.RequestBody.Value.Content["multipart/form-data"].Schema.Value
+	require.Equal(t, "string", schema.Properties["Avatar"].Value.Type)
+	require.Equal(t, "binary", schema.Properties["Avatar"].Value.Format)
+	require.Equal(t, "array", schema.Properties["Photos"].Value.Type)
+	require.Equal(t, "binary", schema.Properties["Photos"].Value.Items.Value.Format)
+}
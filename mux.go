@@ -41,6 +41,7 @@ func Group(s *Server, path string) *Server {
 
 type Route[ResponseBody any, RequestBody any] struct {
 	operation *openapi3.Operation
+	server    *Server // Used to register the schemas of additional responses.
 }
 
 const MethodAll = "ALL"
@@ -72,10 +73,7 @@ func Patch[T any, B any, Contexted ctx[B]](s *Server, path string, controller fu
 
 // Registers route into the default mux.
 func Register[T any, B any, Contexted ctx[B]](s *Server, method string, path string, controller func(Contexted) (T, error), middlewares ...func(http.Handler) http.Handler) Route[T, B] {
-	fullPath := s.basePath + path
-	if isGo1_22 {
-		fullPath = method + " " + path
-	}
+	fullPath := method + " " + path
 	slog.Debug("registering openapi controller " + fullPath)
 
 	route := register[T, B](s, method, path, httpHandler[T, B](s, controller), middlewares...)
@@ -84,25 +82,40 @@ func Register[T any, B any, Contexted ctx[B]](s *Server, method string, path str
 	route.operation.Summary = name
 	route.operation.Description = "controller: " + nameWithPath
 	route.operation.OperationID = fullPath + ":" + name
+
+	if withParams, ok := any(*new(Contexted)).(interface{ paramsType() reflect.Type }); ok {
+		documentQueryParams(route.operation, withParams.paramsType())
+		documentPathParams(route.operation, withParams.paramsType())
+	}
+
 	return route
 }
 
 func register[T any, B any](s *Server, method string, path string, controller http.Handler, middlewares ...func(http.Handler) http.Handler) Route[T, B] {
 	fullPath := s.basePath + path
-	if isGo1_22 && method != MethodAll {
+	if method != MethodAll {
 		fullPath = method + " " + fullPath
 	}
 
-	allMiddlewares := append(middlewares, s.middlewares...)
-	s.Mux.Handle(fullPath, withMiddlewares(controller, allMiddlewares...))
-
 	operation, err := RegisterOpenAPIOperation[T, B](s, method, s.basePath+path)
 	if err != nil {
 		slog.Warn("error documenting openapi operation", "error", err)
 	}
 
+	allMiddlewares := append(middlewares, s.middlewares...)
+	handler := withRoutePattern(s.wrapRoute(controller, allMiddlewares, operation), s.basePath+path)
+	if method != http.MethodOptions || !s.routes.replaceOptionsRoute(s.basePath+path, handler) {
+		s.Mux.Handle(fullPath, handler)
+	}
+
+	s.routes.add(method, s.basePath+path)
+	if s.optionsRoutes && method != http.MethodOptions {
+		s.registerOptionsRoute(s.basePath+path, allMiddlewares)
+	}
+
 	return Route[T, B]{
 		operation: operation,
+		server:    s,
 	}
 }
 
@@ -151,6 +164,57 @@ func (r Route[ResponseBody, RequestBody]) WithQueryParam(name, description strin
 	return r
 }
 
+// WithPathParam documents the path parameter with the given name.
+// The parameter must be declared in the route pattern, for example /recipes/{id}.
+// By default, path parameters are documented as strings.
+// Example:
+//
+//	fuego.Get(s, "/recipes/{id}", getRecipe).WithPathParam("id", "ID of the recipe", fuego.ParamInteger)
+func (r Route[ResponseBody, RequestBody]) WithPathParam(name, description string, paramType ParamType) Route[ResponseBody, RequestBody] {
+	parameter := r.operation.Parameters.GetByInAndName(openapi3.ParameterInPath, name)
+	if parameter == nil {
+		slog.Warn("path parameter not declared in route pattern", "name", name)
+		return r
+	}
+	parameter.Description = description
+	parameter.Schema = paramType.schema().NewRef()
+	return r
+}
+
+// AddResponse documents an additional response of the route, with the given status code and body type.
+// Use a nil responseType for responses without body.
+// Example:
+//
+//	fuego.Post(s, "/recipes", createRecipe).
+//		AddResponse(http.StatusCreated, "Recipe created", Recipe{}).
+//		AddResponse(http.StatusAccepted, "Recipe will be created later", nil)
+func (r Route[ResponseBody, RequestBody]) AddResponse(code int, description string, responseType any) Route[ResponseBody, RequestBody] {
+	response := openapi3.NewResponse().WithDescription(description)
+	if responseType != nil {
+		tag := tagFromType(responseType)
+		schema, err := r.server.getOrCreateSchema(tag, responseType)
+		if err != nil {
+			slog.Warn("error documenting response", "code", code, "error", err)
+			return r
+		}
+		response.WithContent(newContentWithSchemaRef(tag, schema, r.server.responseMediaTypes(reflect.TypeOf(responseType))))
+	}
+	r.operation.AddResponse(code, response)
+	return r
+}
+
+// AddError documents an error response of the route, with the given status code.
+// The body is documented like the default error response: as an [HTTPError], with the message only in XML and text/plain,
+// or as a [ProblemDetails] if [WithProblemDetails] is used.
+// Example:
+//
+//	fuego.Get(s, "/recipes/{id}", getRecipe).
+//		AddError(http.StatusNotFound, "Recipe not found")
+func (r Route[ResponseBody, RequestBody]) AddError(code int, description string) Route[ResponseBody, RequestBody] {
+	r.operation.AddResponse(code, r.server.newErrorResponse(description))
+	return r
+}
+
 func UseStd(s *Server, middlewares ...func(http.Handler) http.Handler) {
 	Use(s, middlewares...)
 }
@@ -187,10 +251,7 @@ func PatchStd(s *Server, path string, controller func(http.ResponseWriter, *http
 
 // RegisterStd registers a standard http handler into the default mux.
 func RegisterStd(s *Server, method string, path string, controller func(http.ResponseWriter, *http.Request), middlewares ...func(http.Handler) http.Handler) Route[any, any] {
-	fullPath := s.basePath + path
-	if isGo1_22 {
-		fullPath = method + " " + path
-	}
+	fullPath := method + " " + path
 	slog.Debug("registering standard controller " + fullPath)
 	route := register[any, any](s, method, path, http.HandlerFunc(controller), middlewares...)
 
@@ -201,6 +262,27 @@ func RegisterStd(s *Server, method string, path string, controller func(http.Res
 	return route
 }
 
+// wrapRoute wraps the handler of a route with its middlewares, and the middlewares enabled by the server options.
+func (s *Server) wrapRoute(handler http.Handler, middlewares []func(http.Handler) http.Handler, operation *openapi3.Operation) http.Handler {
+	handler = withMiddlewares(handler, middlewares...)
+	if !s.recoverConfig.Disabled {
+		handler = s.recoverMiddleware(handler)
+	}
+	if s.metrics != nil {
+		handler = s.metrics.middleware(handler)
+	}
+	if s.accessLog {
+		handler = accessLogMiddleware(s.logger, handler)
+	}
+	if s.tracer != nil {
+		handler = tracingMiddleware(s.tracer, handler, operation)
+	}
+	if s.requestID {
+		handler = requestIDMiddleware(handler)
+	}
+	return handler
+}
+
 func withMiddlewares(controller http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	for _, middleware := range middlewares {
 		controller = middleware(controller)
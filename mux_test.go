@@ -294,6 +294,50 @@ func TestWithQueryParams(t *testing.T) {
 	require.Equal(t, "my description", route.operation.Parameters.GetByInAndName("query", "my-param").Description)
 }
 
+func TestWithPathParam(t *testing.T) {
+	s := NewServer()
+	route := Get(s, "/test/{id}/{slug}", func(ctx *ContextNoBody) (string, error) {
+		return "test", nil
+	}).
+		WithPathParam("id", "my description", ParamInteger).
+		WithPathParam("unknown", "not in the pattern", ParamInteger)
+
+	id := route.operation.Parameters.GetByInAndName("path", "id")
+	require.Equal(t, "my description", id.Description)
+	require.Equal(t, "integer", id.Schema.Value.Type)
+
+	slug := route.operation.Parameters.GetByInAndName("path", "slug")
+	require.Equal(t, "string", slug.Schema.Value.Type)
+
+	require.Nil(t, route.operation.Parameters.GetByInAndName("path", "unknown"))
+}
+
+func TestAddResponse(t *testing.T) {
+	s := NewServer()
+	route := Post(s, "/test", func(ctx *ContextWithBody[MyStruct]) (MyStruct, error) {
+		return MyStruct{}, nil
+	}).
+		AddResponse(201, "created", MyOutputStruct{}).
+		AddResponse(204, "no content", nil).
+		AddError(409, "conflict")
+
+	created := route.operation.Responses.Value("201")
+	require.NotNil(t, created)
+	require.Equal(t, "created", *created.Value.Description)
+	require.Equal(t, "#/components/schemas/MyOutputStruct", created.Value.Content["application/json"].Schema.Ref)
+	require.Contains(t, s.OpenApiSpec.Components.Schemas, "MyOutputStruct")
+
+	noContent := route.operation.Responses.Value("204")
+	require.NotNil(t, noContent)
+	require.Empty(t, noContent.Value.Content)
+
+	conflict := route.operation.Responses.Value("409")
+	require.NotNil(t, conflict)
+	require.Equal(t, "#/components/schemas/HTTPError", conflict.Value.Content["application/json"].Schema.Ref)
+
+	require.NotNil(t, route.operation.Responses.Value("200"), "the default response is kept")
+}
+
 func BenchmarkRequest(b *testing.B) {
 	type Resp struct {
 		Name string `json:"name"`
@@ -469,3 +513,75 @@ func TestGroup(t *testing.T) {
 		require.Equal(t, "", w.Header().Get("X-Test-Response"), "middleware is not inherited")
 	})
 }
+
+func TestHandleOptions(t *testing.T) {
+	s := NewServer()
+	Get(s, "/before", func(c *ContextNoBody) (string, error) { return "", nil })
+	HandleOptions(s)
+	var allowed []string
+	Use(s, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			allowed = AllowedMethods(r.Context())
+			next.ServeHTTP(w, r)
+		})
+	})
+	RegisterStd(s, http.MethodOptions, "/custom", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	Get(s, "/custom", func(c *ContextNoBody) (string, error) { return "", nil })
+	Get(s, "/recipes", func(c *ContextNoBody) (string, error) { return "", nil })
+	Post(s, "/recipes", func(c *ContextNoBody) (string, error) { return "", nil })
+
+	t.Run("allowed methods", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/recipes", nil))
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "GET, HEAD, POST, OPTIONS", w.Header().Get("Allow"))
+		require.Equal(t, []string{"GET", "HEAD", "POST", "OPTIONS"}, allowed, "available to the middlewares")
+	})
+
+	t.Run("not in other routes", func(t *testing.T) {
+		s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/recipes", nil))
+		require.Nil(t, allowed)
+	})
+
+	t.Run("user OPTIONS route is kept", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/custom", nil))
+		require.Equal(t, http.StatusTeapot, w.Code)
+	})
+
+	t.Run("routes registered before are not changed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/before", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+
+	t.Run("same path with other wildcard names", func(t *testing.T) {
+		Get(s, "/ingredients/{id}", func(c *ContextNoBody) (string, error) { return "", nil })
+		Delete(s, "/ingredients/{name}", func(c *ContextNoBody) (string, error) { return "", nil })
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/ingredients/1", nil))
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "GET, HEAD, DELETE, OPTIONS", w.Header().Get("Allow"))
+	})
+
+	t.Run("user OPTIONS route registered afterwards", func(t *testing.T) {
+		Get(s, "/menus/{id}", func(c *ContextNoBody) (string, error) { return "", nil })
+		RegisterStd(s, http.MethodOptions, "/menus/{menu}", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Menu", r.PathValue("menu"))
+			w.WriteHeader(http.StatusTeapot)
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/menus/12", nil))
+		require.Equal(t, http.StatusTeapot, w.Code, "replaces the automatic route")
+		require.Equal(t, "12", w.Header().Get("X-Menu"))
+
+		require.Panics(t, func() {
+			RegisterStd(s, http.MethodOptions, "/menus/{id}", func(w http.ResponseWriter, r *http.Request) {})
+		}, "user routes cannot be registered twice")
+	})
+}
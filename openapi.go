@@ -11,9 +11,14 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/google/uuid"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -99,8 +104,8 @@ func generateSwagger(s *Server, jsonSpec []byte) {
 		httpSwagger.URL(s.OpenapiConfig.JsonSpecUrl), // The url pointing to API definition
 	))
 
-	slog.Info(fmt.Sprintf("Raw json spec available at http://localhost%s%s", s.Server.Addr, s.OpenapiConfig.JsonSpecUrl))
-	slog.Info(fmt.Sprintf("OpenAPI generated at http://localhost%s%s/index.html", s.Server.Addr, s.OpenapiConfig.SwaggerUrl))
+	slog.Info(fmt.Sprintf("Raw json spec available at %s%s", s.url(), s.OpenapiConfig.JsonSpecUrl))
+	slog.Info(fmt.Sprintf("OpenAPI generated at %s%s/index.html", s.url(), s.OpenapiConfig.SwaggerUrl))
 }
 
 func validateJsonSpecLocalPath(jsonSpecLocalPath string) bool {
@@ -120,8 +125,17 @@ func validateSwaggerUrl(swaggerUrl string) bool {
 
 var generator = openapi3gen.NewGenerator(
 	openapi3gen.UseAllExportedFields(),
+	openapi3gen.SchemaCustomizer(customizeSchema),
 )
 
+// customizeSchema documents the uploaded files of multipart/form-data bodies as binary strings.
+func customizeSchema(_ string, t reflect.Type, _ reflect.StructTag, schema *openapi3.Schema) error {
+	if t == fileHeaderType.Elem() {
+		*schema = *openapi3.NewStringSchema().WithFormat("binary")
+	}
+	return nil
+}
+
 func RegisterOpenAPIOperation[T any, B any](s *Server, method, path string) (*openapi3.Operation, error) {
 	operation := openapi3.NewOperation()
 
@@ -135,14 +149,9 @@ func RegisterOpenAPIOperation[T any, B any](s *Server, method, path string) (*op
 	bodyTag := tagFromType(*new(B))
 	if (method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch) && bodyTag != "unknown-interface" && bodyTag != "string" {
 
-		bodySchema, ok := s.OpenApiSpec.Components.Schemas[bodyTag]
-		if !ok {
-			var err error
-			bodySchema, err = generator.NewSchemaRefForValue(new(B), s.OpenApiSpec.Components.Schemas)
-			if err != nil {
-				return operation, err
-			}
-			s.OpenApiSpec.Components.Schemas[bodyTag] = bodySchema
+		bodySchema, err := s.getOrCreateSchema(bodyTag, new(B))
+		if err != nil {
+			return operation, err
 		}
 
 		requestBody := openapi3.NewRequestBody().
@@ -150,9 +159,7 @@ func RegisterOpenAPIOperation[T any, B any](s *Server, method, path string) (*op
 			WithDescription("Request body for " + reflect.TypeOf(*new(B)).String())
 
 		if bodySchema != nil {
-			content := openapi3.NewContentWithSchema(bodySchema.Value, []string{"application/json"})
-			content["application/json"].Schema.Ref = "#/components/schemas/" + bodyTag
-			requestBody.WithContent(content)
+			requestBody.WithContent(newContentWithSchemaRef(bodyTag, bodySchema, s.requestMediaTypes()))
 		}
 
 		s.OpenApiSpec.Components.RequestBodies[bodyTag] = &openapi3.RequestBodyRef{
@@ -167,26 +174,26 @@ func RegisterOpenAPIOperation[T any, B any](s *Server, method, path string) (*op
 	}
 
 	// Response body
-	responseSchema, ok := s.OpenApiSpec.Components.Schemas[tag]
-	if !ok {
-		var err error
-		responseSchema, err = generator.NewSchemaRefForValue(new(T), s.OpenApiSpec.Components.Schemas)
-		if err != nil {
-			return operation, err
-		}
-		s.OpenApiSpec.Components.Schemas[tag] = responseSchema
+	responseSchema, err := s.getOrCreateSchema(tag, new(T))
+	if err != nil {
+		return operation, err
 	}
 
 	response := openapi3.NewResponse().WithDescription("OK")
 	if responseSchema != nil {
-		content := openapi3.NewContentWithSchema(responseSchema.Value, []string{"application/json"})
-		content["application/json"].Schema.Ref = "#/components/schemas/" + tag
-		response.WithContent(content)
+		response.WithContent(newContentWithSchemaRef(tag, responseSchema, s.responseMediaTypes(reflect.TypeOf(new(T)).Elem())))
 	}
 	operation.AddResponse(200, response)
 
+	// Error responses
+	operation.Responses.Set("default", &openapi3.ResponseRef{Value: s.newErrorResponse("Error")})
+
 	// Path parameters
 	for _, pathParam := range parsePathParams(path) {
+		pathParam = strings.TrimSuffix(pathParam, "...")
+		if pathParam == "$" {
+			continue
+		}
 		parameter := openapi3.NewPathParameter(pathParam)
 		parameter.Schema = openapi3.NewStringSchema().NewRef()
 		operation.AddParameter(parameter)
@@ -195,11 +202,287 @@ func RegisterOpenAPIOperation[T any, B any](s *Server, method, path string) (*op
 	if method == MethodAll {
 		method = http.MethodGet
 	}
-	s.OpenApiSpec.AddOperation(path, method, operation)
+	s.OpenApiSpec.AddOperation(openAPIPath(path), method, operation)
 
 	return operation, nil
 }
 
+// openAPIPath returns the path of the route in the OpenAPI spec, without the wildcards specific to [http.ServeMux]:
+// /static/{path...} becomes /static/{path}, and /recipes/{$} becomes /recipes/
+func openAPIPath(path string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(path, "{$}", ""), "...}", "}")
+}
+
+// getOrCreateSchema returns the schema registered in the components with the given name,
+// or generates it from the given value and registers it.
+func (s *Server) getOrCreateSchema(name string, v any) (*openapi3.SchemaRef, error) {
+	schema, ok := s.OpenApiSpec.Components.Schemas[name]
+	if !ok {
+		var err error
+		schema, err = generator.NewSchemaRefForValue(v, s.OpenApiSpec.Components.Schemas)
+		if err != nil {
+			return nil, err
+		}
+		s.OpenApiSpec.Components.Schemas[name] = schema
+	}
+	return schema, nil
+}
+
+// newContentWithSchemaRef returns a content for each media type, referencing the component schema with the given name.
+func newContentWithSchemaRef(name string, schema *openapi3.SchemaRef, mediaTypes []string) openapi3.Content {
+	content := openapi3.NewContentWithSchema(schema.Value, mediaTypes)
+	for _, mediaType := range content {
+		mediaType.Schema.Ref = "#/components/schemas/" + name
+	}
+	return content
+}
+
+// newErrorResponse returns a response documenting the error body, sent with any of the registered serializers.
+// See [HTTPError], or [ProblemDetails] if enabled with [WithProblemDetails].
+func (s *Server) newErrorResponse(description string) *openapi3.Response {
+	response := openapi3.NewResponse().WithDescription(description)
+	if s.problemDetails {
+		mediaTypes := make([]string, 0, len(s.serializersOrder))
+		for _, mediaType := range s.serializersOrder {
+			mediaTypes = append(mediaTypes, problemDetailsMediaType(mediaType))
+		}
+		return response.WithContent(newErrorContent("ProblemDetails", s.problemDetailsSchema(), mediaTypes))
+	}
+	content := newErrorContent("HTTPError", s.httpErrorSchema(), s.serializersOrder)
+	if _, ok := content["application/xml"]; ok {
+		content["application/xml"] = newContentWithSchemaRef("HTTPErrorXML", s.httpErrorXMLSchema(), []string{"application/xml"})["application/xml"]
+	}
+	return response.WithContent(content)
+}
+
+// newErrorContent returns a content for each media type, referencing the component error schema with the given name.
+// Errors sent as text/plain are the error message only, see [SendTextError].
+func newErrorContent(name string, schema *openapi3.SchemaRef, mediaTypes []string) openapi3.Content {
+	content := newContentWithSchemaRef(name, schema, mediaTypes)
+	if _, ok := content["text/plain"]; ok {
+		content["text/plain"] = openapi3.NewMediaType().WithSchema(describedStringSchema("Human readable error message"))
+	}
+	return content
+}
+
+// httpErrorXMLSchema registers and returns the schema of [HTTPError] as serialized by [SendXMLError]:
+// the message only, as the additional information is not sent in XML.
+func (s *Server) httpErrorXMLSchema() *openapi3.SchemaRef {
+	if schema, ok := s.OpenApiSpec.Components.Schemas["HTTPErrorXML"]; ok {
+		return schema
+	}
+
+	errorSchema := openapi3.NewObjectSchema().
+		WithProperty("Error", describedStringSchema("Human readable error message"))
+	errorSchema.Required = []string{"Error"}
+	errorSchema.XML = &openapi3.XML{Name: "HTTPError"}
+	schema := errorSchema.NewRef()
+	s.OpenApiSpec.Components.Schemas["HTTPErrorXML"] = schema
+
+	return schema
+}
+
+// httpErrorSchema registers and returns the schema of [HTTPError] as serialized by [SendJSONError].
+// It is written by hand, because the Err field is not meant for the client.
+// The validation errors of the request body or query parameters are in info.validation.
+// In XML, errors are documented with [Server.httpErrorXMLSchema].
+func (s *Server) httpErrorSchema() *openapi3.SchemaRef {
+	if schema, ok := s.OpenApiSpec.Components.Schemas["HTTPError"]; ok {
+		return schema
+	}
+
+	info := openapi3.NewObjectSchema().
+		WithProperty("validation", s.validationErrorsSchema()).
+		WithAnyAdditionalProperties()
+	info.Description = "Additional information about the error"
+
+	errorSchema := openapi3.NewObjectSchema().
+		WithProperty("error", describedStringSchema("Human readable error message")).
+		WithProperty("info", info)
+	errorSchema.Required = []string{"error"}
+	schema := errorSchema.NewRef()
+	s.OpenApiSpec.Components.Schemas["HTTPError"] = schema
+
+	return schema
+}
+
+// problemDetailsSchema registers and returns the schema of [ProblemDetails], as defined in RFC 9457.
+// The validation errors of the request body or query parameters are in the errors extension member.
+func (s *Server) problemDetailsSchema() *openapi3.SchemaRef {
+	if schema, ok := s.OpenApiSpec.Components.Schemas["ProblemDetails"]; ok {
+		return schema
+	}
+
+	status := openapi3.NewIntegerSchema()
+	status.Description = "HTTP status code"
+
+	problemSchema := openapi3.NewObjectSchema().
+		WithProperty("type", describedStringSchema("URI reference identifying the problem type").WithFormat("uri-reference")).
+		WithProperty("title", describedStringSchema("Short human readable summary of the problem type")).
+		WithProperty("status", status).
+		WithProperty("detail", describedStringSchema("Human readable explanation of this occurrence of the problem")).
+		WithProperty("instance", describedStringSchema("URI reference identifying this occurrence of the problem").WithFormat("uri-reference")).
+		WithProperty("errors", s.validationErrorsSchema()).
+		WithAnyAdditionalProperties()
+	problemSchema.Required = []string{"type", "title", "status"}
+	schema := problemSchema.NewRef()
+	s.OpenApiSpec.Components.Schemas["ProblemDetails"] = schema
+
+	return schema
+}
+
+// validationErrorsSchema registers the schema of the validation errors, and returns the schema of a list of them.
+func (s *Server) validationErrorsSchema() *openapi3.Schema {
+	validationErrorSchema, ok := s.OpenApiSpec.Components.Schemas["ValidationError"]
+	if !ok {
+		var err error
+		validationErrorSchema, err = generator.NewSchemaRefForValue(fieldValidationError{}, s.OpenApiSpec.Components.Schemas)
+		if err != nil {
+			slog.Warn("error documenting validation errors", "error", err)
+			validationErrorSchema = openapi3.NewObjectSchema().NewRef()
+		}
+		s.OpenApiSpec.Components.Schemas["ValidationError"] = validationErrorSchema
+	}
+
+	validationErrors := openapi3.NewArraySchema()
+	validationErrors.Items = openapi3.NewSchemaRef("#/components/schemas/ValidationError", validationErrorSchema.Value)
+	validationErrors.Description = "Fields that failed validation"
+	return validationErrors
+}
+
+func describedStringSchema(description string) *openapi3.Schema {
+	schema := openapi3.NewStringSchema()
+	schema.Description = description
+	return schema
+}
+
+// responseMediaTypes returns the media types a route returning the given type can produce.
+// HTML, strings and renderers are sent as is, other types are serialized with any of the registered serializers.
+func (s *Server) responseMediaTypes(returnType reflect.Type) []string {
+	switch {
+	case returnType == reflect.TypeOf(HTML("")),
+		returnType.Implements(reflect.TypeOf((*CtxRenderer)(nil)).Elem()),
+		returnType.Implements(reflect.TypeOf((*Renderer)(nil)).Elem()):
+		return []string{"text/html"}
+	case returnType.Kind() == reflect.String, returnType.Kind() == reflect.Ptr && returnType.Elem().Kind() == reflect.String:
+		return []string{"text/plain"}
+	default:
+		return s.serializableMediaTypes(returnType)
+	}
+}
+
+// requestMediaTypes returns the media types accepted for a structured request body.
+// text/plain is left out, as it can only be read into a string, and string bodies are not documented.
+func (s *Server) requestMediaTypes() []string {
+	mediaTypes := make([]string, 0, len(s.deserializersOrder))
+	for _, mediaType := range s.deserializersOrder {
+		if mediaType != "text/plain" {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	return mediaTypes
+}
+
+// documentQueryParams adds each field of the given struct to the operation, as a typed query parameter.
+// See [ContextWithParams] for the supported tags.
+func documentQueryParams(operation *openapi3.Operation, paramsType reflect.Type) {
+	if paramsType.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, field := range queryFields(paramsType) {
+		name := queryParamName(field)
+		if name == "" {
+			continue
+		}
+
+		schema := paramSchema(field.Type)
+		if defaultValue, ok := field.Tag.Lookup("default"); ok {
+			schema.Default = typedDefault(schema, defaultValue)
+		}
+
+		parameter := openapi3.NewQueryParameter(name)
+		parameter.Description = field.Tag.Get("description")
+		parameter.Required = slices.Contains(strings.Split(field.Tag.Get("validate"), ","), "required")
+		parameter.Schema = schema.NewRef()
+		operation.AddParameter(parameter)
+	}
+}
+
+// documentPathParams sets the type of the path parameters bound to a field of the given struct with the `path` tag.
+// Other path parameters stay documented as strings. See [ContextWithParams] for the supported tags.
+func documentPathParams(operation *openapi3.Operation, paramsType reflect.Type) {
+	if paramsType.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, field := range pathFields(paramsType) {
+		parameter := operation.Parameters.GetByInAndName(openapi3.ParameterInPath, field.Tag.Get("path"))
+		if parameter == nil {
+			continue
+		}
+		parameter.Description = field.Tag.Get("description")
+		parameter.Schema = paramSchema(field.Type).NewRef()
+	}
+}
+
+// paramSchema returns the OpenAPI schema of a query or path parameter from its Go type.
+func paramSchema(t reflect.Type) *openapi3.Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return openapi3.NewDateTimeSchema()
+	case reflect.TypeOf(uuid.UUID{}):
+		return ParamUUID.schema()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return ParamBool.schema()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return ParamInteger.schema()
+	case reflect.Float32, reflect.Float64:
+		return ParamNumber.schema()
+	case reflect.Slice, reflect.Array:
+		return openapi3.NewArraySchema().WithItems(paramSchema(t.Elem()))
+	default:
+		return ParamString.schema()
+	}
+}
+
+// typedDefault converts the `default` tag value to the type of the schema, so the spec stays valid.
+// Default values of arrays are comma separated.
+func typedDefault(schema *openapi3.Schema, value string) any {
+	var err error
+	var typed any
+	switch schema.Type {
+	case openapi3.TypeInteger:
+		typed, err = strconv.Atoi(value)
+	case openapi3.TypeNumber:
+		typed, err = strconv.ParseFloat(value, 64)
+	case openapi3.TypeBoolean:
+		typed, err = strconv.ParseBool(value)
+	case openapi3.TypeArray:
+		values := strings.Split(value, ",")
+		items := make([]any, 0, len(values))
+		for _, v := range values {
+			items = append(items, typedDefault(schema.Items.Value, v))
+		}
+		typed = items
+	default:
+		typed = value
+	}
+	if err != nil {
+		slog.Warn("default value does not match the type of the parameter", "default", value, "type", schema.Type)
+		return value
+	}
+	return typed
+}
+
 func tagFromType(v any) string {
 	if v == nil {
 		return "unknown-interface"
@@ -219,6 +502,22 @@ func dive(t reflect.Type, maxDepth int) string {
 		}
 		return dive(t.Elem(), maxDepth-1)
 	default:
-		return t.Name()
+		return genericTypeName(t.Name())
+	}
+}
+
+var (
+	packagePathRegex       = regexp.MustCompile(`[\w./-]*\.`)
+	invalidSchemaNameRegex = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// genericTypeName turns the name of an instantiated generic type into a valid OpenAPI component name,
+// by removing the package paths and the brackets.
+// Example: Page[github.com/me/app.Recipe] -> PageRecipe
+func genericTypeName(name string) string {
+	if !strings.Contains(name, "[") {
+		return name
 	}
+	name = packagePathRegex.ReplaceAllString(name, "")
+	return invalidSchemaNameRegex.ReplaceAllString(name, "")
 }
@@ -1,11 +1,14 @@
 package fuego
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,7 +53,9 @@ func TestTagFromType(t *testing.T) {
 }
 
 func TestServer_generateOpenAPI(t *testing.T) {
-	s := NewServer()
+	s := NewServer(
+		WithOpenapiConfig(OpenapiConfig{DisableLocalSave: true}),
+	)
 	Get(s, "/", func(*ContextNoBody) (MyStruct, error) {
 		return MyStruct{}, nil
 	})
@@ -60,15 +65,51 @@ func TestServer_generateOpenAPI(t *testing.T) {
 	Get(s, "/post/{id}", func(*ContextNoBody) (MyOutputStruct, error) {
 		return MyOutputStruct{}, nil
 	})
+	GetStd(s, "/files/{path...}", func(http.ResponseWriter, *http.Request) {})
+	GetStd(s, "/exact/{$}", func(http.ResponseWriter, *http.Request) {})
 	document := s.generateOpenAPI()
 	require.NotNil(t, document)
 	require.NotNil(t, document.Paths.Find("/"))
 	require.Nil(t, document.Paths.Find("/unknown"))
 	require.NotNil(t, document.Paths.Find("/post"))
+	require.NotNil(t, document.Paths.Find("/post").Post.RequestBody.Value.Content["application/json"])
+	require.NotNil(t, document.Paths.Find("/post").Post.RequestBody.Value.Content["application/x-www-form-urlencoded"])
+	require.Nil(t, document.Paths.Find("/post").Post.RequestBody.Value.Content["text/plain"])
 	require.NotNil(t, document.Paths.Find("/post/{id}").Get.Responses.Value("200"))
 	require.NotNil(t, document.Paths.Find("/post/{id}").Get.Responses.Value("200").Value.Content["application/json"])
 	require.Nil(t, document.Paths.Find("/post/{id}").Get.Responses.Value("200").Value.Content["application/json"].Schema.Value.Properties["unknown"])
 	require.Equal(t, document.Paths.Find("/post/{id}").Get.Responses.Value("200").Value.Content["application/json"].Schema.Value.Properties["quantity"].Value.Type, "integer")
+	require.NotNil(t, document.Paths.Find("/post/{id}").Get.Responses.Value("200").Value.Content["application/xml"])
+	require.Nil(t, document.Paths.Find("/post/{id}").Get.Responses.Value("200").Value.Content["text/plain"], "structs have no text representation")
+
+	t.Run("errors are documented", func(t *testing.T) {
+		defaultResponse := document.Paths.Find("/post").Post.Responses.Default()
+		require.NotNil(t, defaultResponse)
+		require.Equal(t, "#/components/schemas/HTTPError", defaultResponse.Value.Content["application/json"].Schema.Ref)
+		textError := defaultResponse.Value.Content["text/plain"].Schema
+		require.Empty(t, textError.Ref, "text errors are the message only")
+		require.Equal(t, "string", textError.Value.Type)
+
+		xmlError := defaultResponse.Value.Content["application/xml"].Schema
+		require.Equal(t, "#/components/schemas/HTTPErrorXML", xmlError.Ref)
+		require.Equal(t, "HTTPError", xmlError.Value.XML.Name)
+		require.Equal(t, []string{"Error"}, xmlError.Value.Required)
+		require.NotContains(t, xmlError.Value.Properties, "info", "the additional information is not sent in XML")
+
+		httpError := document.Components.Schemas["HTTPError"].Value
+		require.Equal(t, []string{"error"}, httpError.Required)
+		validation := httpError.Properties["info"].Value.Properties["validation"].Value
+		require.Equal(t, "#/components/schemas/ValidationError", validation.Items.Ref)
+		require.Contains(t, document.Components.Schemas["ValidationError"].Value.Properties, "devField")
+	})
+
+	t.Run("wildcards of the paths", func(t *testing.T) {
+		files := document.Paths.Value("/files/{path}")
+		require.NotNil(t, files)
+		require.NotNil(t, files.Get.Parameters.GetByInAndName("path", "path"))
+		require.Nil(t, document.Paths.Value("/files/{path...}"))
+		require.NotNil(t, document.Paths.Value("/exact/"))
+	})
 
 	t.Run("openapi doc is available through a route", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -79,10 +120,66 @@ func TestServer_generateOpenAPI(t *testing.T) {
 	})
 }
 
+func TestDocumentQueryParams(t *testing.T) {
+	type params struct {
+		Search string   `query:"search" description:"Search in names" validate:"required"`
+		Limit  int      `query:"limit" default:"10"`
+		Price  *float64 `query:"price"`
+		Tags   []int    `query:"tags" default:"1,2"`
+		Ignore string   `query:"-"`
+	}
+
+	s := NewServer()
+	route := Get(s, "/params", func(*ContextWithParams[params]) (MyStruct, error) {
+		return MyStruct{}, nil
+	})
+
+	search := route.operation.Parameters.GetByInAndName("query", "search")
+	require.Equal(t, "string", search.Schema.Value.Type)
+	require.Equal(t, "Search in names", search.Description)
+	require.True(t, search.Required)
+
+	limit := route.operation.Parameters.GetByInAndName("query", "limit")
+	require.Equal(t, "integer", limit.Schema.Value.Type)
+	require.Equal(t, 10, limit.Schema.Value.Default)
+	require.False(t, limit.Required)
+
+	price := route.operation.Parameters.GetByInAndName("query", "price")
+	require.Equal(t, "number", price.Schema.Value.Type)
+
+	tags := route.operation.Parameters.GetByInAndName("query", "tags")
+	require.Equal(t, "array", tags.Schema.Value.Type)
+	require.Equal(t, "integer", tags.Schema.Value.Items.Value.Type)
+	require.Equal(t, []any{1, 2}, tags.Schema.Value.Default)
+
+	require.Nil(t, route.operation.Parameters.GetByInAndName("query", "Ignore"))
+
+	require.NoError(t, s.OpenApiSpec.Validate(context.Background()))
+
+	t.Run("typed path params", func(t *testing.T) {
+		type pathParams struct {
+			AuthorID uuid.UUID `path:"author_id" description:"Author of the recipes"`
+			ID       int       `path:"id"`
+		}
+		route := Get(s, "/authors/{author_id}/recipes/{id}/{slug}", func(*ContextWithParams[pathParams]) (MyStruct, error) {
+			return MyStruct{}, nil
+		})
+
+		authorID := route.operation.Parameters.GetByInAndName("path", "author_id")
+		require.Equal(t, "string", authorID.Schema.Value.Type)
+		require.Equal(t, "uuid", authorID.Schema.Value.Format)
+		require.Equal(t, "Author of the recipes", authorID.Description)
+		require.Equal(t, "integer", route.operation.Parameters.GetByInAndName("path", "id").Schema.Value.Type)
+		require.Equal(t, "string", route.operation.Parameters.GetByInAndName("path", "slug").Schema.Value.Type)
+		require.Nil(t, route.operation.Parameters.GetByInAndName("query", "AuthorID"))
+	})
+}
+
 func BenchmarkRoutesRegistration(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		s := NewServer(
 			WithoutLogger(),
+			WithOpenapiConfig(OpenapiConfig{DisableLocalSave: true}),
 		)
 		Get(s, "/", func(ContextNoBody) (MyStruct, error) {
 			return MyStruct{}, nil
@@ -104,6 +201,7 @@ func BenchmarkServer_generateOpenAPI(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		s := NewServer(
 			WithoutLogger(),
+			WithOpenapiConfig(OpenapiConfig{DisableLocalSave: true}),
 		)
 		Get(s, "/", func(ContextNoBody) (MyStruct, error) {
 			return MyStruct{}, nil
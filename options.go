@@ -1,22 +1,22 @@
 package fuego
 
 import (
+	"crypto/tls"
 	"html/template"
 	"io"
 	"io/fs"
 	"log/slog"
 	"net/http"
 	"os"
-	"runtime"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var isGo1_22 = strings.TrimPrefix(runtime.Version(), "devel ") >= "go1.22"
-
 type OpenapiConfig struct {
 	DisableSwagger    bool
 	DisableLocalSave  bool
@@ -54,11 +54,38 @@ type Server struct {
 
 	DisallowUnknownFields bool // If true, the server will return an error if the request body contains unknown fields. Useful for quick debugging in development.
 	maxBodySize           int64
-	Serialize             func(w http.ResponseWriter, ans any)   // Used to serialize the response. Defaults to [SendJSON].
-	SerializeError        func(w http.ResponseWriter, err error) // Used to serialize the error response. Defaults to [SendJSONError].
+	Serialize             func(w http.ResponseWriter, ans any)   // Used to serialize the response when the client accepts any media type. Defaults to [SendJSON].
+	SerializeError        func(w http.ResponseWriter, err error) // Used to serialize the error response when the client accepts any media type. Defaults to [SendJSONError].
+	serializers           map[string]Serializer                  // Serializers by media type, chosen from the Accept header. See [WithMediaTypeSerializer].
+	serializersOrder      []string                               // Media types in registration order, for wildcards (ex: text/*) and the OpenAPI spec.
+	deserializers         map[string]Deserializer                // Request body deserializers by media type, chosen from the Content-Type header. See [WithMediaTypeDeserializer].
+	deserializersOrder    []string                               // Media types in registration order, for the OpenAPI spec.
 	ErrorHandler          func(err error) error                  // Used to transform any error into a unified error type structure with status code. Defaults to [ErrorHandler]
+	problemDetails        bool                                   // Errors are documented as [ProblemDetails] in the OpenAPI spec. See [WithProblemDetails].
+	sseHeartbeat          time.Duration                          // Interval between the keep-alive comments of Server-Sent Events. See [WithSSEHeartbeat].
+	shutdownTimeout       time.Duration                          // Maximum time to drain the in-flight requests on shutdown. See [WithShutdownTimeout].
+	shutdownSignals       []os.Signal                            // Signals that trigger a graceful shutdown. See [WithShutdownSignals].
+	shutdownDelay         time.Duration                          // Time during which the server reports not ready before draining. See [WithShutdownDelay].
+	shutdownDelaySet      bool                                   // Whether the shutdown delay was set with [WithShutdownDelay], or defaults with the health checks.
+	lifecycle             *lifecycle                             // Start and shutdown hooks, shared with the groups.
+	health                *health                                // Health checks, if enabled. See [WithHealthChecks].
+	metrics               *metrics                               // Request metrics, if enabled. See [WithMetrics].
+	tracer                Tracer                                 // Request tracing, if enabled. See [WithTracer].
+	requestID             bool                                   // See [WithRequestID].
+	logger                *slog.Logger                           // Logger of the access log, the panics and the controllers, see [Ctx.Logger].
+	accessLog             bool                                   // See [WithAccessLog].
+	recoverConfig         RecoverConfig                          // See [WithRecoverConfig].
+	routes                *routeRegistry                         // Methods registered by path, shared with the groups.
+	assets                *staticAssets                          // File systems served with [Static], shared with the groups.
+	optionsRoutes         bool                                   // See [HandleOptions].
 	startTime             time.Time
 
+	tlsCertFile      string      // See [WithTLS].
+	tlsKeyFile       string      // See [WithTLS].
+	tlsConfig        *tls.Config // See [WithTLSConfig].
+	disableHTTP2     bool        // See [WithoutHTTP2].
+	httpRedirectAddr string      // See [WithHTTPRedirect].
+
 	OpenapiConfig OpenapiConfig
 }
 
@@ -86,28 +113,50 @@ func NewServer(options ...func(*Server)) *Server {
 		OpenapiConfig: defaultOpenapiConfig,
 
 		Security: NewSecurity(),
+
+		serializers:   make(map[string]Serializer),
+		deserializers: make(map[string]Deserializer),
+		lifecycle:     newLifecycle(),
+		routes:        newRouteRegistry(),
+		assets:        newStaticAssets(),
 	}
 
 	defaultOptions := [...]func(*Server){
 		WithPort(":9999"),
+		WithSSEHeartbeat(15 * time.Second),
+		WithShutdownTimeout(25 * time.Second),
+		WithShutdownSignals(os.Interrupt, syscall.SIGTERM),
 		WithDisallowUnknownFields(true),
 		WithSerializer(SendJSON),
 		WithErrorSerializer(SendJSONError),
 		WithErrorHandler(ErrorHandler),
+		WithMediaTypeSerializer("application/json", SendJSON, SendJSONError),
+		WithMediaTypeSerializer("application/xml", SendXML, SendXMLError),
+		WithMediaTypeSerializer("text/plain", SendText, SendTextError),
+		WithMediaTypeDeserializer("application/json", DeserializeJSON),
+		WithMediaTypeDeserializer("application/x-www-form-urlencoded", DeserializeURLEncoded),
+		WithMediaTypeDeserializer("multipart/form-data", DeserializeMultipart),
+		WithMediaTypeDeserializer("text/plain", DeserializeText),
 	}
 
 	for _, option := range append(defaultOptions[:], options...) {
 		option(s)
 	}
 
-	if !isGo1_22 {
-		slog.Warn(
-			"Please upgrade to Go >= 1.22. " +
-				"You are running " + runtime.Version() + ": " +
-				"you cannot use path params nor register routes with the same path but different methods. ")
+	s.startTime = time.Now()
+
+	s.logger = newServerLogger(s.requestID)
+
+	if s.health != nil {
+		if !s.shutdownDelaySet {
+			s.shutdownDelay = defaultHealthShutdownDelay
+		}
+		s.registerHealthRoutes()
 	}
 
-	s.startTime = time.Now()
+	if s.metrics != nil {
+		s.registerMetricsRoute()
+	}
 
 	if s.autoAuth.Enabled {
 		Post(s, "/auth/login", s.Security.LoginHandler(s.autoAuth.VerifyUserInfo)).SetTags("Auth").WithSummary("Login")
@@ -141,13 +190,14 @@ func WithTemplateFS(fs fs.FS) func(*Server) {
 
 // WithTemplates loads the templates used to render HTML.
 // To be used with [WithTemplateFS]. If not set, it will use the os filesystem, at folder "./templates".
+// The "asset" function of the templates is set, see [Server.AssetURL].
 func WithTemplates(templates *template.Template) func(*Server) {
 	return func(s *Server) {
 		if s.fs == nil {
 			s.fs = os.DirFS("./templates")
 			slog.Warn("No template filesystem set. Using os filesystem at './templates'.")
 		}
-		s.template = templates
+		s.template = templates.Funcs(s.assets.templateFuncs())
 
 		slog.Debug("Loaded templates", "templates", s.template.DefinedTemplates())
 	}
@@ -225,6 +275,44 @@ func WithSerializer(serializer func(w http.ResponseWriter, ans any)) func(*Serve
 	return func(c *Server) { c.Serialize = serializer }
 }
 
+// WithMediaTypeSerializer registers the serializers used when the client asks for the given media type in the Accept header.
+// JSON (application/json), XML (application/xml) and plain text (text/plain) are registered by default.
+// Registering an already registered media type replaces its serializers. Media types are case-insensitive.
+// For example:
+//
+//	fuego.WithMediaTypeSerializer("application/x-yaml", SendYAML, SendYAMLError)
+func WithMediaTypeSerializer(mediaType string, serializer func(w http.ResponseWriter, ans any), errorSerializer func(w http.ResponseWriter, err error)) func(*Server) {
+	mediaType = strings.ToLower(mediaType)
+	return func(s *Server) {
+		if _, exists := s.serializers[mediaType]; !exists {
+			s.serializersOrder = append(s.serializersOrder, mediaType)
+		}
+		s.serializers[mediaType] = Serializer{
+			Serialize:      serializer,
+			SerializeError: errorSerializer,
+		}
+	}
+}
+
+// WithMediaTypeDeserializer registers the deserializer used to read request bodies with the given media type in the Content-Type header.
+// JSON, HTML forms (application/x-www-form-urlencoded and multipart/form-data) and plain text are registered by default.
+// Requests with an unregistered media type are answered with 415 Unsupported Media Type.
+// Registering an already registered media type replaces its deserializer. Media types are case-insensitive.
+// For example:
+//
+//	fuego.WithMediaTypeDeserializer("application/xml", func(r *http.Request, body any, _ fuego.DeserializeOptions) error {
+//		return xml.NewDecoder(r.Body).Decode(body)
+//	})
+func WithMediaTypeDeserializer(mediaType string, deserializer Deserializer) func(*Server) {
+	mediaType = strings.ToLower(mediaType)
+	return func(s *Server) {
+		if _, exists := s.deserializers[mediaType]; !exists {
+			s.deserializersOrder = append(s.deserializersOrder, mediaType)
+		}
+		s.deserializers[mediaType] = deserializer
+	}
+}
+
 func WithErrorSerializer(serializer func(w http.ResponseWriter, err error)) func(*Server) {
 	return func(c *Server) { c.SerializeError = serializer }
 }
@@ -233,6 +321,189 @@ func WithErrorHandler(errorHandler func(err error) error) func(*Server) {
 	return func(c *Server) { c.ErrorHandler = errorHandler }
 }
 
+// WithProblemDetails sends all the errors in the Problem Details format, as defined in RFC 9457:
+// application/problem+json, or application/problem+xml if the client asks for XML.
+// It replaces the error handler by [ProblemDetailsErrorHandler], and documents the errors as [ProblemDetails] in the OpenAPI spec.
+// The instance member defaults to the path of the request.
+func WithProblemDetails() func(*Server) {
+	return func(s *Server) {
+		s.ErrorHandler = ProblemDetailsErrorHandler
+		s.problemDetails = true
+	}
+}
+
+// WithSSEHeartbeat sets the interval between the comments sent to keep Server-Sent Events connections alive.
+// Proxies and load balancers often close idle connections. 0 disables the heartbeat.
+// Defaults to 15 seconds.
+func WithSSEHeartbeat(interval time.Duration) func(*Server) {
+	return func(s *Server) { s.sseHeartbeat = interval }
+}
+
+// WithShutdownTimeout sets the maximum time to wait for the in-flight requests to complete during a graceful shutdown.
+// After this time, the remaining connections are closed.
+// Keep it lower than the termination grace period of your orchestrator (30 seconds by default on Kubernetes).
+// Defaults to 25 seconds.
+func WithShutdownTimeout(timeout time.Duration) func(*Server) {
+	return func(s *Server) { s.shutdownTimeout = timeout }
+}
+
+// WithShutdownDelay sets the time during which the server keeps serving requests on shutdown,
+// but reports not ready on the readiness route (see [WithHealthChecks]), before draining the in-flight requests.
+// It lets load balancers notice that the server is going away and stop sending it new requests.
+// The delay is part of the shutdown timeout, see [WithShutdownTimeout].
+// Defaults to 5 seconds with [WithHealthChecks], so that the readiness probes see the server going away, and to 0 otherwise.
+func WithShutdownDelay(delay time.Duration) func(*Server) {
+	return func(s *Server) {
+		s.shutdownDelay = delay
+		s.shutdownDelaySet = true
+	}
+}
+
+// WithShutdownSignals sets the signals that trigger a graceful shutdown of the server started with [Server.Run].
+// Without signals, the server only shuts down when the context given to [Server.RunContext] is canceled.
+// Defaults to SIGINT and SIGTERM.
+func WithShutdownSignals(signals ...os.Signal) func(*Server) {
+	return func(s *Server) { s.shutdownSignals = signals }
+}
+
+// WithTLS serves HTTPS with the given certificate and key files, in PEM format.
+// The certificate is reloaded when the files change on disk, so renewed certificates are served without restarting.
+// HTTP/2 is enabled, unless [WithoutHTTP2] is used.
+// Can be combined with [WithTLSConfig] to customize the TLS configuration.
+// For example:
+//
+//	fuego.NewServer(
+//		fuego.WithPort(":443"),
+//		fuego.WithTLS("/etc/certs/fullchain.pem", "/etc/certs/privkey.pem"),
+//		fuego.WithHTTPRedirect(":80"),
+//	)
+func WithTLS(certFile, keyFile string) func(*Server) {
+	return func(s *Server) {
+		s.tlsCertFile = certFile
+		s.tlsKeyFile = keyFile
+	}
+}
+
+// WithTLSConfig serves HTTPS with the given TLS configuration.
+// The configuration must provide the certificates (Certificates or GetCertificate fields), unless [WithTLS] is used.
+// Defaults to a configuration accepting TLS 1.2 and above.
+func WithTLSConfig(config *tls.Config) func(*Server) {
+	return func(s *Server) { s.tlsConfig = config }
+}
+
+// WithoutHTTP2 disables HTTP/2 when serving HTTPS. Only HTTP/1.1 is served.
+func WithoutHTTP2() func(*Server) {
+	return func(s *Server) { s.disableHTTP2 = true }
+}
+
+// WithHTTPRedirect starts a plain HTTP listener on the given address (ex: ":80"),
+// that permanently redirects all requests to HTTPS. Only used with [WithTLS] or [WithTLSConfig].
+func WithHTTPRedirect(addr string) func(*Server) {
+	return func(s *Server) { s.httpRedirectAddr = addr }
+}
+
+// WithHealthChecks registers a liveness route (/health/live) and a readiness route (/health/ready).
+// The liveness route reports that the server is running.
+// The readiness route runs the given checks concurrently, and responds with 503 Service Unavailable if any of them fails,
+// or if the server is shutting down: [Server.Shutdown] waits 5 seconds before draining the requests,
+// for the probes to notice it. The delay can be changed with [WithShutdownDelay].
+// The paths can be changed with [WithHealthConfig].
+// For example:
+//
+//	fuego.WithHealthChecks(
+//		fuego.HealthCheck{Name: "database", Check: db.PingContext, Timeout: time.Second},
+//	)
+func WithHealthChecks(checks ...HealthCheck) func(*Server) {
+	return func(s *Server) {
+		if s.health == nil {
+			s.health = &health{config: defaultHealthConfig}
+		}
+		for _, check := range checks {
+			s.health.checkers = append(s.health.checkers, newHealthChecker(check))
+		}
+	}
+}
+
+// WithHealthConfig sets the paths of the health routes, and enables them. See [WithHealthChecks].
+func WithHealthConfig(config HealthConfig) func(*Server) {
+	return func(s *Server) {
+		if config.LivenessPath == "" {
+			config.LivenessPath = defaultHealthConfig.LivenessPath
+		}
+		if config.ReadinessPath == "" {
+			config.ReadinessPath = defaultHealthConfig.ReadinessPath
+		}
+		if s.health == nil {
+			s.health = &health{}
+		}
+		s.health.config = config
+	}
+}
+
+// WithMetrics records the duration of the requests and of their phases (the ones of the Server-Timing header),
+// by route pattern, method and status, and exposes them in the Prometheus text format at the configured path.
+// Example:
+//
+//	s := fuego.NewServer(
+//		fuego.WithMetrics(fuego.MetricsConfig{Path: "/internal/metrics"}),
+//	)
+func WithMetrics(config MetricsConfig) func(*Server) {
+	return func(s *Server) {
+		if config.Path == "" {
+			config.Path = defaultMetricsConfig.Path
+		}
+		if len(config.Buckets) == 0 {
+			config.Buckets = defaultMetricsConfig.Buckets
+		}
+		config.Buckets = slices.Clone(config.Buckets)
+		slices.Sort(config.Buckets)
+		s.metrics = newMetrics(config)
+	}
+}
+
+// WithTracer enables tracing: a server span is started for each request,
+// named after the method and the route pattern, and continuing the trace of the request headers if any.
+// The deserialization, the transformations, the controller and the rendering are traced as child spans.
+// The github.com/go-fuego/fuego/otel module provides an OpenTelemetry tracer:
+//
+//	s := fuego.NewServer(
+//		fuego.WithTracer(fuegootel.NewTracer(otel.GetTracerProvider())),
+//	)
+func WithTracer(tracer Tracer) func(*Server) {
+	return func(s *Server) { s.tracer = tracer }
+}
+
+// WithRequestID identifies each request with the X-Request-ID header sent by the client, or a generated UUID if absent or invalid.
+// The ID is sent back in the response, and is available with [Ctx.RequestID] or [RequestIDFromContext].
+// It is added to the records logged by the server (access log, errors and recovered panics),
+// and to the records of the logger of the controllers:
+//
+//	c.Logger().Info("Recipe created", "id", recipe.ID) // ... request_id=0b9e...
+//
+// Only [Ctx.Logger] carries the ID: the records of the package-level functions like slog.Info do not,
+// as the default logger is not modified.
+func WithRequestID() func(*Server) {
+	return func(s *Server) { s.requestID = true }
+}
+
+// WithAccessLog logs a record for each request with the default logger at the creation of the server, with the method, the route pattern,
+// the path, the status, the size of the response body and the latency.
+// Use with [WithRequestID] to add the request ID to the records.
+func WithAccessLog() func(*Server) {
+	return func(s *Server) { s.accessLog = true }
+}
+
+// WithRecoverConfig configures the recovery from the panics of the controllers and of the route middlewares.
+// By default, a panic is logged with its stack trace, and a 500 error is sent with the error handler and the error serializer.
+// Example, to see the stack trace in the responses during development:
+//
+//	s := fuego.NewServer(
+//		fuego.WithRecoverConfig(fuego.RecoverConfig{Debug: os.Getenv("ENV") == "dev"}),
+//	)
+func WithRecoverConfig(config RecoverConfig) func(*Server) {
+	return func(s *Server) { s.recoverConfig = config }
+}
+
 // WithoutLogger disables the default logger.
 func WithoutLogger() func(*Server) {
 	return func(c *Server) {
@@ -0,0 +1,85 @@
+// Package otel traces the requests of a Fuego server with OpenTelemetry. It is a separate module,
+// so that the servers without tracing do not depend on OpenTelemetry. Example:
+//
+//	import fuegootel "github.com/go-fuego/fuego/otel"
+//	...
+//	s := fuego.NewServer(
+//		fuego.WithTracer(fuegootel.NewTracer(otel.GetTracerProvider())),
+//	)
+package otel
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-fuego/fuego"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/go-fuego/fuego"
+
+// Tracer is a [fuego.Tracer] starting OpenTelemetry spans.
+// The server spans continue the trace of the W3C traceparent header of the requests.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+var _ fuego.Tracer = Tracer{}
+
+// NewTracer returns a tracer starting the spans with the given provider.
+func NewTracer(provider trace.TracerProvider) Tracer {
+	return Tracer{
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+func (t Tracer) StartRequest(r *http.Request, route, operationID string) (context.Context, func(status int)) {
+	attributes := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(r.Method),
+		semconv.HTTPRoute(route),
+		semconv.URLPath(r.URL.Path),
+	}
+	if operationID != "" {
+		attributes = append(attributes, attribute.String("fuego.operation_id", operationID))
+	}
+
+	ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	ctx, span := t.tracer.Start(ctx, r.Method+" "+route,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attributes...),
+	)
+
+	return ctx, func(status int) {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		// Client errors are not errors of the server, see https://opentelemetry.io/docs/specs/semconv/http/http-spans/#status
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		span.End()
+	}
+}
+
+func (t Tracer) StartSpan(ctx context.Context, name string) (context.Context, func(err error)) {
+	ctx, span := t.tracer.Start(ctx, name)
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+func (t Tracer) RecordError(ctx context.Context, err error, status int) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.RecordError(err, trace.WithAttributes(semconv.HTTPResponseStatusCode(status)))
+}
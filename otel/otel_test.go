@@ -0,0 +1,136 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracedBody struct {
+	Name string `json:"name"`
+}
+
+func (b *tracedBody) InTransform(ctx context.Context) error {
+	if b.Name == "" {
+		return fuego.BadRequestError{Message: "name is required"}
+	}
+	b.Name = strings.ToUpper(b.Name)
+	return nil
+}
+
+func (b *tracedBody) OutTransform(context.Context) error {
+	b.Name = strings.ToLower(b.Name)
+	return nil
+}
+
+func newTracedServer(t *testing.T) (*fuego.Server, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	s := fuego.NewServer(fuego.WithTracer(NewTracer(provider)))
+	return s, exporter
+}
+
+func spanNames(spans tracetest.SpanStubs) []string {
+	names := make([]string, 0, len(spans))
+	for _, span := range spans {
+		names = append(names, span.Name)
+	}
+	return names
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing(t *testing.T) {
+	t.Run("server span and phases", func(t *testing.T) {
+		s, exporter := newTracedServer(t)
+		fuego.Post(s, "/recipes/{id}", func(c *fuego.ContextWithBody[tracedBody]) (tracedBody, error) {
+			_, span := trace.SpanFromContext(c.Context()).TracerProvider().Tracer("test").Start(c.Context(), "database")
+			span.End()
+			return c.Body()
+		})
+
+		r := httptest.NewRequest("POST", "/recipes/1", strings.NewReader(`{"name":"Pizza"}`))
+		r.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, 200, w.Code, w.Body.String())
+
+		spans := exporter.GetSpans()
+		require.Equal(t, []string{"database", "deserialize", "transformIn", "controller", "transformOut", "serialize", "POST /recipes/{id}"}, spanNames(spans))
+
+		server := spans[len(spans)-1]
+		require.Equal(t, trace.SpanKindServer, server.SpanKind)
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String(), "continues the trace of the traceparent header")
+		require.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+		require.True(t, server.Parent.IsRemote())
+		require.Equal(t, "/recipes/{id}", spanAttribute(server, "http.route").AsString())
+		require.Contains(t, spanAttribute(server, "fuego.operation_id").AsString(), "POST /recipes/{id}:")
+		require.Equal(t, int64(200), spanAttribute(server, "http.response.status_code").AsInt64())
+		require.Equal(t, codes.Unset, server.Status.Code)
+
+		controller := spans[3]
+		require.Equal(t, server.SpanContext.SpanID(), controller.Parent.SpanID())
+		for _, child := range spans[:3] {
+			require.Equal(t, controller.SpanContext.SpanID(), child.Parent.SpanID(), child.Name+" is a child of the controller span")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		s, exporter := newTracedServer(t)
+		fuego.Post(s, "/recipes", func(c *fuego.ContextWithBody[tracedBody]) (tracedBody, error) {
+			return c.Body()
+		})
+		fuego.Get(s, "/boom", func(c *fuego.ContextNoBody) (any, error) {
+			return nil, errors.New("boom")
+		})
+
+		t.Run("client error", func(t *testing.T) {
+			defer exporter.Reset()
+			s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/recipes", strings.NewReader(`{}`)))
+
+			spans := exporter.GetSpans()
+			require.Equal(t, []string{"deserialize", "transformIn", "controller", "POST /recipes"}, spanNames(spans))
+			require.Equal(t, codes.Error, spans[1].Status.Code)
+			require.Contains(t, spans[1].Status.Description, "name is required")
+
+			server := spans[3]
+			require.Equal(t, codes.Unset, server.Status.Code, "4xx are not server errors")
+			require.Equal(t, int64(400), spanAttribute(server, "http.response.status_code").AsInt64())
+			require.Len(t, server.Events, 1)
+			require.Equal(t, "exception", server.Events[0].Name)
+		})
+
+		t.Run("server error", func(t *testing.T) {
+			defer exporter.Reset()
+			s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/boom", nil))
+
+			spans := exporter.GetSpans()
+			require.Equal(t, []string{"controller", "GET /boom"}, spanNames(spans))
+			server := spans[1]
+			require.Equal(t, codes.Error, server.Status.Code)
+			require.Equal(t, int64(500), spanAttribute(server, "http.response.status_code").AsInt64())
+			require.Len(t, server.Events, 1)
+		})
+	})
+
+}
@@ -0,0 +1,184 @@
+package fuego
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OffsetPagination are the query parameters of an offset paginated list.
+// Use it as the Params of a [ContextWithParams], or embed it in your own Params struct,
+// so the parameters are read, validated and documented in the OpenAPI spec.
+// Example:
+//
+//	type RecipesParams struct {
+//		fuego.OffsetPagination
+//		Search string `query:"search"`
+//	}
+//
+//	fuego.Get(s, "/recipes", func(c *fuego.ContextWithParams[RecipesParams]) (fuego.Page[Recipe], error) {
+//		params, err := c.Params()
+//		if err != nil {
+//			return fuego.Page[Recipe]{}, err
+//		}
+//		recipes, total, err := rs.SearchRecipes(c.Context(), params.Search, params.Offset, params.Limit)
+//		if err != nil {
+//			return fuego.Page[Recipe]{}, err
+//		}
+//		return fuego.OffsetPage(c, recipes, params.OffsetPagination, total), nil
+//	})
+type OffsetPagination struct {
+	Offset int `query:"offset" default:"0" validate:"min=0" description:"Number of items to skip"`
+	Limit  int `query:"limit" default:"20" validate:"min=1,max=100" description:"Maximum number of items to return"`
+}
+
+// CursorPagination are the query parameters of a cursor paginated list.
+// The cursor is opaque to the client: it is read from the next and prev links of the previous page.
+// See [OffsetPagination] for usage.
+type CursorPagination struct {
+	Cursor string `query:"cursor" description:"Cursor of the page, from the next or prev link of another page. First page if empty."`
+	Limit  int    `query:"limit" default:"20" validate:"min=1,max=100" description:"Maximum number of items to return"`
+}
+
+// Page is a page of a paginated list.
+// Build it with [OffsetPage] or [CursorPage], that also set the Link header (RFC 8288).
+type Page[T any] struct {
+	XMLName    xml.Name `json:"-" xml:"page"`
+	Items      []T      `json:"items" xml:"items>item"`
+	Limit      int      `json:"limit" xml:"limit"`
+	Offset     *int     `json:"offset,omitempty" xml:"offset,omitempty"`         // Only for offset pagination.
+	Total      *int     `json:"total,omitempty" xml:"total,omitempty"`           // Only if the total number of items is known.
+	NextCursor string   `json:"nextCursor,omitempty" xml:"nextCursor,omitempty"` // Only for cursor pagination.
+	PrevCursor string   `json:"prevCursor,omitempty" xml:"prevCursor,omitempty"` // Only for cursor pagination.
+	Next       string   `json:"next,omitempty" xml:"next,omitempty"`             // URL of the next page, if any.
+	Prev       string   `json:"prev,omitempty" xml:"prev,omitempty"`             // URL of the previous page, if any.
+}
+
+// requestResponder is implemented by all the contexts.
+type requestResponder interface {
+	Request() *http.Request
+	Response() http.ResponseWriter
+}
+
+// OffsetPage returns the page of items read with the given pagination, and sets the Link header
+// with the first, prev, next and last pages.
+// Use a negative total if the total number of items is unknown:
+// there is no last page, and there is a next page as long as the page is full.
+func OffsetPage[T any](c requestResponder, items []T, pagination OffsetPagination, total int) Page[T] {
+	page := Page[T]{
+		Items:  nonNilItems(items),
+		Limit:  pagination.Limit,
+		Offset: &pagination.Offset,
+	}
+
+	links := pageLinks{}
+	offsetURL := func(offset int) string {
+		return pageURL(c.Request(), map[string]string{
+			"offset": strconv.Itoa(offset),
+			"limit":  strconv.Itoa(pagination.Limit),
+		})
+	}
+
+	links.first = offsetURL(0)
+	if pagination.Offset > 0 {
+		page.Prev = offsetURL(max(pagination.Offset-pagination.Limit, 0))
+	}
+	if total >= 0 {
+		page.Total = &total
+		if pagination.Offset+pagination.Limit < total {
+			page.Next = offsetURL(pagination.Offset + pagination.Limit)
+		}
+		if pagination.Limit > 0 && total > 0 {
+			links.last = offsetURL((total - 1) / pagination.Limit * pagination.Limit)
+		}
+	} else if pagination.Limit > 0 && len(items) >= pagination.Limit {
+		page.Next = offsetURL(pagination.Offset + pagination.Limit)
+	}
+
+	links.prev, links.next = page.Prev, page.Next
+	links.setHeader(c.Response())
+
+	return page
+}
+
+// CursorPage returns the page of items read with the given pagination, and sets the Link header
+// with the first, prev and next pages.
+// The cursors are opaque to the client. Use an empty cursor if there is no next or previous page.
+func CursorPage[T any](c requestResponder, items []T, pagination CursorPagination, nextCursor, prevCursor string) Page[T] {
+	page := Page[T]{
+		Items:      nonNilItems(items),
+		Limit:      pagination.Limit,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+	}
+
+	cursorURL := func(cursor string) string {
+		return pageURL(c.Request(), map[string]string{
+			"cursor": cursor,
+			"limit":  strconv.Itoa(pagination.Limit),
+		})
+	}
+
+	links := pageLinks{first: cursorURL("")}
+	if nextCursor != "" {
+		page.Next = cursorURL(nextCursor)
+	}
+	if prevCursor != "" {
+		page.Prev = cursorURL(prevCursor)
+	}
+
+	links.prev, links.next = page.Prev, page.Next
+	links.setHeader(c.Response())
+
+	return page
+}
+
+// nonNilItems makes sure an empty page is serialized as an empty list, not as null.
+func nonNilItems[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// pageURL returns the URL of the request (path and query), with the given query parameters replaced.
+// Empty values remove the parameter.
+// The URL is relative to the host, as allowed by RFC 8288, so it is correct behind a proxy.
+func pageURL(r *http.Request, params map[string]string) string {
+	query := r.URL.Query()
+	for name, value := range params {
+		if value == "" {
+			query.Del(name)
+		} else {
+			query.Set(name, value)
+		}
+	}
+
+	u := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return u.String()
+}
+
+type pageLinks struct {
+	first, prev, next, last string
+}
+
+// setHeader sets the Link header, as defined in RFC 8288.
+// Example: Link: </recipes?limit=20&offset=0>; rel="first", </recipes?limit=20&offset=40>; rel="next"
+func (l pageLinks) setHeader(w http.ResponseWriter) {
+	links := make([]string, 0, 4)
+	for _, link := range []struct{ rel, url string }{
+		{"first", l.first},
+		{"prev", l.prev},
+		{"next", l.next},
+		{"last", l.last},
+	} {
+		if link.url != "" {
+			links = append(links, "<"+link.url+`>; rel="`+link.rel+`"`)
+		}
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,150 @@
+package fuego
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type paginatedParams struct {
+	OffsetPagination
+	Search string `query:"search"`
+}
+
+func TestOffsetPage(t *testing.T) {
+	s := NewServer()
+	Get(s, "/items", func(c *ContextWithParams[paginatedParams]) (Page[int], error) {
+		params, err := c.Params()
+		if err != nil {
+			return Page[int]{}, err
+		}
+		items := []int{}
+		for i := params.Offset; i < min(params.Offset+params.Limit, 45); i++ {
+			items = append(items, i)
+		}
+		return OffsetPage(c, items, params.OffsetPagination, 45), nil
+	})
+
+	t.Run("first page with default params", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/items?search=a", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 200, w.Code)
+		require.Equal(t, `</items?limit=20&offset=0&search=a>; rel="first", </items?limit=20&offset=20&search=a>; rel="next", </items?limit=20&offset=40&search=a>; rel="last"`, w.Header().Get("Link"))
+		require.JSONEq(t, `{
+			"items": [0,1,2,3,4,5,6,7,8,9,10,11,12,13,14,15,16,17,18,19],
+			"limit": 20,
+			"offset": 0,
+			"total": 45,
+			"next": "/items?limit=20&offset=20&search=a"
+		}`, w.Body.String())
+	})
+
+	t.Run("last page", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/items?offset=40&limit=10", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 200, w.Code)
+		require.Equal(t, `</items?limit=10&offset=0>; rel="first", </items?limit=10&offset=30>; rel="prev", </items?limit=10&offset=40>; rel="last"`, w.Header().Get("Link"))
+		require.JSONEq(t, `{
+			"items": [40,41,42,43,44],
+			"limit": 10,
+			"offset": 40,
+			"total": 45,
+			"prev": "/items?limit=10&offset=30"
+		}`, w.Body.String())
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/items?limit=1000", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 400, w.Code)
+	})
+
+	t.Run("unknown total", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/items?offset=5&limit=10", nil)
+		w := httptest.NewRecorder()
+		c := NewContext[any](w, r, readOptions{})
+
+		page := OffsetPage(c, []int{5, 6, 7, 8, 9, 10, 11, 12, 13, 14}, OffsetPagination{Offset: 5, Limit: 10}, -1)
+
+		require.Nil(t, page.Total)
+		require.Equal(t, "/items?limit=10&offset=0", page.Prev)
+		require.Equal(t, "/items?limit=10&offset=15", page.Next)
+		require.Equal(t, `</items?limit=10&offset=0>; rel="first", </items?limit=10&offset=0>; rel="prev", </items?limit=10&offset=15>; rel="next"`, w.Header().Get("Link"))
+
+		page = OffsetPage[int](c, nil, OffsetPagination{Offset: 20, Limit: 10}, -1)
+		require.Empty(t, page.Next)
+		require.NotNil(t, page.Items)
+	})
+}
+
+func TestCursorPage(t *testing.T) {
+	s := NewServer()
+	Get(s, "/items", func(c *ContextWithParams[CursorPagination]) (Page[int], error) {
+		params, err := c.Params()
+		if err != nil {
+			return Page[int]{}, err
+		}
+		start, _ := strconv.Atoi(params.Cursor)
+		items := []int{}
+		for i := start; i < start+params.Limit; i++ {
+			items = append(items, i)
+		}
+		prev := ""
+		if start > 0 {
+			prev = strconv.Itoa(max(start-params.Limit, 0))
+		}
+		return CursorPage(c, items, params, strconv.Itoa(start+params.Limit), prev), nil
+	})
+
+	r := httptest.NewRequest("GET", "/items?cursor=3&limit=2", nil)
+	w := httptest.NewRecorder()
+
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, 200, w.Code)
+	require.Equal(t, `</items?limit=2>; rel="first", </items?cursor=1&limit=2>; rel="prev", </items?cursor=5&limit=2>; rel="next"`, w.Header().Get("Link"))
+	require.JSONEq(t, `{
+		"items": [3,4],
+		"limit": 2,
+		"nextCursor": "5",
+		"prevCursor": "1",
+		"next": "/items?cursor=5&limit=2",
+		"prev": "/items?cursor=1&limit=2"
+	}`, w.Body.String())
+}
+
+func TestPageOpenAPI(t *testing.T) {
+	s := NewServer()
+	route := Get(s, "/items", func(c *ContextWithParams[paginatedParams]) (Page[MyStruct], error) {
+		return Page[MyStruct]{}, nil
+	})
+
+	require.Contains(t, s.OpenApiSpec.Components.Schemas, "PageMyStruct")
+	schema := s.OpenApiSpec.Components.Schemas["PageMyStruct"].Value
+	require.Contains(t, schema.Properties, "items")
+	require.Contains(t, schema.Properties, "next")
+	require.NotContains(t, schema.Properties, "XMLName")
+
+	limit := route.operation.Parameters.GetByInAndName("query", "limit")
+	require.NotNil(t, limit)
+	require.Equal(t, 20, limit.Schema.Value.Default)
+	require.NotNil(t, route.operation.Parameters.GetByInAndName("query", "offset"))
+	require.NotNil(t, route.operation.Parameters.GetByInAndName("query", "search"))
+}
+
+func TestGenericTypeName(t *testing.T) {
+	require.Equal(t, "Recipe", genericTypeName("Recipe"))
+	require.Equal(t, "PageRecipe", genericTypeName("Page[github.com/go-fuego/fuego.Recipe]"))
+	require.Equal(t, "Pairstringint", genericTypeName("Pair[string,int]"))
+}
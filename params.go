@@ -1,8 +1,12 @@
 package fuego
 
 import (
+	"context"
+	"net/http"
 	"regexp"
 	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
 )
 
 var pathParamRegex = regexp.MustCompile(`{(.+?)}`)
@@ -16,3 +20,79 @@ func parsePathParams(path string) []string {
 	}
 	return matches
 }
+
+// ParamType is the type of a parameter, as documented in the OpenAPI spec.
+type ParamType string
+
+const (
+	ParamString  ParamType = "string"
+	ParamInteger ParamType = "integer"
+	ParamNumber  ParamType = "number"
+	ParamBool    ParamType = "boolean"
+	ParamUUID    ParamType = "uuid"
+)
+
+// schema returns the OpenAPI schema of the parameter type. Defaults to string.
+func (t ParamType) schema() *openapi3.Schema {
+	switch t {
+	case ParamInteger:
+		return openapi3.NewIntegerSchema()
+	case ParamNumber:
+		return openapi3.NewFloat64Schema()
+	case ParamBool:
+		return openapi3.NewBoolSchema()
+	case ParamUUID:
+		return openapi3.NewUUIDSchema()
+	default:
+		return openapi3.NewStringSchema()
+	}
+}
+
+const contextKeyRoutePattern contextKey = "routePattern"
+
+// withRoutePattern stores the pattern the route was registered with (ex: /recipes/{id}) in the request context.
+func withRoutePattern(handler http.Handler, pattern string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), contextKeyRoutePattern, pattern)
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// routePattern returns the pattern of the route that matched the request, or an empty string.
+func routePattern(ctx context.Context) string {
+	pattern, _ := ctx.Value(contextKeyRoutePattern).(string)
+	return pattern
+}
+
+// PathParamNotFoundError is returned when a path parameter is not declared in the route pattern.
+type PathParamNotFoundError struct {
+	ParamName string
+}
+
+func (e PathParamNotFoundError) Error() string {
+	return "path param " + e.ParamName + " not found"
+}
+
+func (e PathParamNotFoundError) Status() int {
+	return http.StatusBadRequest
+}
+
+// PathParamInvalidTypeError is returned when a path parameter cannot be converted to the expected type.
+type PathParamInvalidTypeError struct {
+	ParamName    string
+	ParamValue   string
+	ExpectedType string
+	Err          error
+}
+
+func (e PathParamInvalidTypeError) Error() string {
+	return "path param " + e.ParamName + "=" + e.ParamValue + " is not of type " + e.ExpectedType + ": " + e.Err.Error()
+}
+
+func (e PathParamInvalidTypeError) Status() int {
+	return http.StatusBadRequest
+}
+
+func (e PathParamInvalidTypeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,201 @@
+package fuego
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ProblemDetails is an error response in the Problem Details format, as defined in RFC 9457.
+// It is sent as application/problem+json by [SendJSONError] and as application/problem+xml by [SendXMLError].
+// Enable it for all errors with the [WithProblemDetails] option. Controllers can then return it to set the type and title of the problem.
+type ProblemDetails struct {
+	XMLName    xml.Name `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type       string   `json:"type" xml:"type"`                             // URI reference identifying the problem type. Defaults to "about:blank".
+	Title      string   `json:"title" xml:"title"`                           // Short human readable summary of the problem type. Defaults to the status text.
+	StatusCode int      `json:"status" xml:"status"`                         // HTTP status code.
+	Detail     string   `json:"detail,omitempty" xml:"detail,omitempty"`     // Human readable explanation of this occurrence of the problem.
+	Instance   string   `json:"instance,omitempty" xml:"instance,omitempty"` // URI reference identifying this occurrence of the problem. Defaults to the request path.
+
+	Errors     []fieldValidationError `json:"errors,omitempty" xml:"error,omitempty"` // Extension member with the validation errors of the request. In XML, each error is an error element.
+	Extensions map[string]any         `json:"-" xml:"-"`                              // Other extension members, serialized at the top level of the JSON object. In XML, members that are not valid element names or cannot be encoded, like maps, are left out.
+	Err        error                  `json:"-" xml:"-"`                              // Developer readable error, not sent to the client.
+}
+
+var (
+	_ ErrorWithInfo   = ProblemDetails{}
+	_ ErrorWithStatus = ProblemDetails{}
+)
+
+func (p ProblemDetails) Error() string {
+	if p.Detail != "" {
+		return p.Detail
+	}
+	return p.Title
+}
+
+func (p ProblemDetails) Status() int {
+	if p.StatusCode == 0 {
+		return http.StatusInternalServerError
+	}
+	return p.StatusCode
+}
+
+func (p ProblemDetails) Info() map[string]any {
+	return p.Extensions
+}
+
+func (p ProblemDetails) Unwrap() error {
+	return p.Err
+}
+
+// MarshalJSON serializes the extension members at the top level of the object, as required by RFC 9457.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	members := make(map[string]any, len(p.Extensions)+6)
+	for name, value := range p.Extensions {
+		members[name] = value
+	}
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.StatusCode
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+	if len(p.Errors) > 0 {
+		members["errors"] = p.Errors
+	}
+	return json.Marshal(members)
+}
+
+// MarshalXML serializes the extension members as child elements of the problem, sorted by name, as described in RFC 9457 appendix B.
+func (p ProblemDetails) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	type problemDetails ProblemDetails // Without the MarshalXML method.
+	return e.Encode(struct {
+		problemDetails
+		Members string `xml:",innerxml"`
+	}{problemDetails(p), extensionsXML(p.Extensions)})
+}
+
+var xmlElementName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]*$`)
+
+// extensionsXML encodes each extension member as an element named after it.
+// Members that would be invalid or shadow a standard member are left out.
+func extensionsXML(extensions map[string]any) string {
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		switch {
+		case !xmlElementName.MatchString(name), strings.HasPrefix(strings.ToLower(name), "xml"):
+		case slices.Contains([]string{"type", "title", "status", "detail", "instance", "error"}, name):
+		default:
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var members strings.Builder
+	for _, name := range names {
+		var member bytes.Buffer
+		encoder := xml.NewEncoder(&member)
+		err := encoder.EncodeElement(extensions[name], xml.StartElement{Name: xml.Name{Local: name}})
+		if err == nil {
+			err = encoder.Flush()
+		}
+		if err != nil {
+			slog.Warn("Cannot serialize problem details extension member in XML", "member", name, "error", err)
+			continue
+		}
+		members.Write(member.Bytes())
+	}
+	return members.String()
+}
+
+// withDefaults fills the members that are required to be meaningful.
+func (p ProblemDetails) withDefaults() ProblemDetails {
+	p.StatusCode = p.Status()
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.StatusCode)
+	}
+	return p
+}
+
+// ProblemDetailsErrorHandler is an error handler that transforms any error into a [ProblemDetails],
+// using the [ErrorWithStatus] and [ErrorWithInfo] interfaces like [ErrorHandler].
+// The validation errors are in the "errors" member, other information in extension members.
+// See [WithProblemDetails].
+func ProblemDetailsErrorHandler(err error) error {
+	var problem ProblemDetails
+	if errors.As(err, &problem) {
+		problem = problem.withDefaults()
+		return problem
+	}
+
+	var httpError HTTPError
+	if !errors.As(ErrorHandler(err), &httpError) {
+		httpError = HTTPError{Message: err.Error()}
+	}
+
+	problem = ProblemDetails{
+		StatusCode: httpError.Status(),
+		Detail:     httpError.Message,
+		Err:        err,
+	}
+	for name, value := range httpError.MoreInfo {
+		if validationErrors, ok := value.([]fieldValidationError); ok && name == "validation" {
+			problem.Errors = validationErrors
+			continue
+		}
+		if problem.Extensions == nil {
+			problem.Extensions = make(map[string]any, len(httpError.MoreInfo))
+		}
+		problem.Extensions[name] = value
+	}
+
+	return problem.withDefaults()
+}
+
+// sendProblemDetails sends the problem with the given media type (application/problem+json or application/problem+xml).
+func sendProblemDetails(w http.ResponseWriter, problem ProblemDetails, mediaType string) {
+	problem = problem.withDefaults()
+
+	var data []byte
+	var err error
+	if mediaType == "application/problem+xml" {
+		data, err = xml.Marshal(problem)
+	} else {
+		data, err = json.Marshal(problem)
+	}
+	if err != nil {
+		slog.Error("Cannot serialize problem details", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(problem.StatusCode)
+	_, _ = w.Write(data)
+}
+
+// problemDetailsMediaType returns the Problem Details media type matching the media type of a serializer,
+// for the OpenAPI spec. Other media types are unchanged.
+func problemDetailsMediaType(mediaType string) string {
+	switch mediaType {
+	case "application/json":
+		return "application/problem+json"
+	case "application/xml":
+		return "application/problem+xml"
+	default:
+		return mediaType
+	}
+}
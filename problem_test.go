@@ -0,0 +1,162 @@
+package fuego
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetailsErrorHandler(t *testing.T) {
+	t.Run("from any error", func(t *testing.T) {
+		err := ProblemDetailsErrorHandler(errors.New("boom"))
+
+		require.Equal(t, ProblemDetails{
+			Type:       "about:blank",
+			Title:      "Internal Server Error",
+			StatusCode: 500,
+			Detail:     "boom",
+			Err:        errors.New("boom"),
+		}, err)
+	})
+
+	t.Run("from an error with status and info", func(t *testing.T) {
+		err := ProblemDetailsErrorHandler(HTTPError{
+			Message:    "not found",
+			StatusCode: 404,
+			MoreInfo:   map[string]any{"id": 12},
+		})
+
+		var problem ProblemDetails
+		require.ErrorAs(t, err, &problem)
+		require.Equal(t, 404, problem.Status())
+		require.Equal(t, "Not Found", problem.Title)
+		require.Equal(t, "not found", problem.Detail)
+		require.Equal(t, map[string]any{"id": 12}, problem.Extensions)
+	})
+
+	t.Run("from a validation error", func(t *testing.T) {
+		err := ProblemDetailsErrorHandler(validate(validatableStruct{Name: "Napoleon", Age: 20, Required: "here", Email: "a@b.c", ExternalID: "not_an_uuid"}))
+
+		var problem ProblemDetails
+		require.ErrorAs(t, err, &problem)
+		require.Equal(t, 400, problem.StatusCode)
+		require.Len(t, problem.Errors, 1)
+		require.Equal(t, "ExternalID", problem.Errors[0].Field)
+		require.Nil(t, problem.Extensions)
+	})
+
+	t.Run("keeps a problem returned by the controller", func(t *testing.T) {
+		err := ProblemDetailsErrorHandler(ProblemDetails{Type: "https://example.com/out-of-stock", Title: "Out of stock", StatusCode: 409})
+
+		require.Equal(t, ProblemDetails{Type: "https://example.com/out-of-stock", Title: "Out of stock", StatusCode: 409}, err)
+	})
+}
+
+func TestSendProblemDetails(t *testing.T) {
+	problem := ProblemDetails{
+		StatusCode: 404,
+		Detail:     "recipe 12 not found",
+		Instance:   "/recipes/12",
+		Extensions: map[string]any{"id": 12},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendJSONError(w, problem)
+
+		require.Equal(t, 404, w.Code)
+		require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		require.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Not Found",
+			"status": 404,
+			"detail": "recipe 12 not found",
+			"instance": "/recipes/12",
+			"id": 12
+		}`, w.Body.String())
+	})
+
+	t.Run("xml", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendXMLError(w, problem)
+
+		require.Equal(t, 404, w.Code)
+		require.Equal(t, "application/problem+xml", w.Header().Get("Content-Type"))
+		require.Equal(t, `<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Not Found</title><status>404</status><detail>recipe 12 not found</detail><instance>/recipes/12</instance><id>12</id></problem>`, w.Body.String())
+	})
+
+	t.Run("xml extensions that cannot be encoded", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendXMLError(w, ProblemDetails{
+			StatusCode: 409,
+			Extensions: map[string]any{"stock": 0, "limits": map[string]int{"max": 3}, "invalid name": true, "status": 200},
+		})
+
+		require.Equal(t, 409, w.Code)
+		require.Equal(t, `<problem xmlns="urn:ietf:rfc:7807"><type>about:blank</type><title>Conflict</title><status>409</status><stock>0</stock></problem>`, w.Body.String())
+	})
+}
+
+func TestWithProblemDetails(t *testing.T) {
+	s := NewServer(WithProblemDetails())
+	Get(s, "/recipes/{id}", func(c *ContextNoBody) (MyStruct, error) {
+		return MyStruct{}, HTTPError{Message: "recipe not found", StatusCode: 404}
+	})
+	Post(s, "/recipes", func(c *ContextWithBody[validatableStruct]) (MyStruct, error) {
+		_, err := c.Body()
+		return MyStruct{}, err
+	})
+
+	t.Run("error", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/recipes/12", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 404, w.Code)
+		require.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		require.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Not Found",
+			"status": 404,
+			"detail": "recipe not found",
+			"instance": "/recipes/12"
+		}`, w.Body.String())
+	})
+
+	t.Run("validation error", func(t *testing.T) {
+		r := httptest.NewRequest("POST", "/recipes", strings.NewReader(`{"Name":"Napoleon","Age":20,"Required":"here","Email":"a@b.c","ExternalID":"not_an_uuid"}`))
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 400, w.Code)
+		require.Contains(t, w.Body.String(), `"errors":[{"devField":"validatableStruct.ExternalID","field":"ExternalID","tag":"uuid","value":"not_an_uuid"}]`)
+	})
+
+	t.Run("xml error", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/recipes/12", nil)
+		r.Header.Set("Accept", "application/xml")
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 404, w.Code)
+		require.Equal(t, "application/problem+xml", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("openapi", func(t *testing.T) {
+		defaultResponse := s.OpenApiSpec.Paths.Find("/recipes/{id}").Get.Responses.Default()
+		require.Equal(t, "#/components/schemas/ProblemDetails", defaultResponse.Value.Content["application/problem+json"].Schema.Ref)
+		require.NotNil(t, defaultResponse.Value.Content["application/problem+xml"])
+		require.Nil(t, defaultResponse.Value.Content["application/json"])
+		require.Equal(t, "string", defaultResponse.Value.Content["text/plain"].Schema.Value.Type)
+
+		problem := s.OpenApiSpec.Components.Schemas["ProblemDetails"].Value
+		require.Equal(t, []string{"type", "title", "status"}, problem.Required)
+		require.Equal(t, "#/components/schemas/ValidationError", problem.Properties["errors"].Value.Items.Ref)
+	})
+}
@@ -0,0 +1,65 @@
+package fuego
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// RecoverConfig is the configuration of the panic recovery. See [WithRecoverConfig].
+type RecoverConfig struct {
+	Disabled bool // If true, panics are not recovered: net/http closes the connection without response.
+	Debug    bool // If true, the panic value and the stack trace are sent in the error response. Do not use in production.
+}
+
+// recoverMiddleware recovers from the panics of the controller and of the route middlewares,
+// and sends a 500 error response with the error handler and the error serializer of the server.
+func (s *Server) recoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writer := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			s.logger.ErrorContext(r.Context(), "Panic recovered", "panic", recovered, "stack", string(stack))
+
+			// The response is partially written: abort it so the client knows it is incomplete.
+			if writer.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+
+			err := panicError(recovered)
+			httpError := HTTPError{
+				Err:        err,
+				Message:    http.StatusText(http.StatusInternalServerError),
+				StatusCode: http.StatusInternalServerError,
+			}
+			if s.recoverConfig.Debug {
+				httpError.MoreInfo = map[string]any{
+					"panic": err.Error(),
+					"stack": string(stack),
+				}
+			}
+
+			serializer, _ := s.negotiateSerializer(r, s.serializersOrder)
+			serializer.SerializeError(w, s.handleError(r, httpError))
+		}()
+
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// panicError converts the value of a panic to an error.
+func panicError(recovered any) error {
+	if err, ok := recovered.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return errors.New("panic: " + fmt.Sprint(recovered))
+}
@@ -0,0 +1,149 @@
+package fuego
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecover(t *testing.T) {
+	logs := captureLogs(t)
+	s := NewServer(
+		WithLogHandler(slog.NewJSONHandler(logs, nil)),
+		WithRequestID(),
+	)
+	Get(s, "/panic", func(c *ContextNoBody) (any, error) {
+		panic("something went wrong")
+	})
+	Post(s, "/must-body", func(c *ContextWithBody[MyStruct]) (MyStruct, error) {
+		return c.MustBody(), nil
+	})
+	Get(s, "/middleware", func(c *ContextNoBody) (any, error) {
+		return nil, nil
+	}, func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			panic(errors.New("middleware error"))
+		})
+	})
+	Get(s, "/partial", func(c *ContextNoBody) (any, error) {
+		c.Response().WriteHeader(http.StatusOK)
+		panic("after the headers")
+	})
+
+	t.Run("panic in a controller", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/panic", nil)
+		r.Header.Set("X-Request-ID", "abc-123")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		var body HTTPError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, "Internal Server Error", body.Message)
+		require.Nil(t, body.MoreInfo, "the panic is not sent outside of debug mode")
+
+		records := logRecords(t, logs, "Panic recovered")
+		require.Len(t, records, 1)
+		require.Equal(t, "something went wrong", records[0]["panic"])
+		require.Contains(t, records[0]["stack"], "recover_test.go")
+		require.Equal(t, "abc-123", records[0]["request_id"])
+	})
+
+	t.Run("panic from MustBody", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest("POST", "/must-body", strings.NewReader("not json")))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Contains(t, w.Body.String(), "Internal Server Error")
+	})
+
+	t.Run("panic in a middleware, negotiated", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/middleware", nil)
+		r.Header.Set("Accept", "application/xml")
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		require.Equal(t, "application/xml", w.Header().Get("Content-Type"))
+	})
+
+	t.Run("panic after the headers are written", func(t *testing.T) {
+		require.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/partial", nil))
+		})
+	})
+}
+
+func TestRecoverConfig(t *testing.T) {
+	t.Run("debug", func(t *testing.T) {
+		s := NewServer(WithRecoverConfig(RecoverConfig{Debug: true}))
+		Get(s, "/panic", func(c *ContextNoBody) (any, error) {
+			panic("something went wrong")
+		})
+
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest("GET", "/panic", nil))
+
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		var body HTTPError
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		require.Equal(t, "panic: something went wrong", body.MoreInfo["panic"])
+		require.Contains(t, body.MoreInfo["stack"], "recover_test.go")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := NewServer(WithRecoverConfig(RecoverConfig{Disabled: true}))
+		Get(s, "/panic", func(c *ContextNoBody) (any, error) {
+			panic("something went wrong")
+		})
+
+		require.Panics(t, func() {
+			s.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/panic", nil))
+		})
+	})
+}
+
+func TestRecoverResponseWriter(t *testing.T) {
+	s := NewServer()
+	GetStd(s, "/stream", func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		_, _ = w.Write([]byte("chunk"))
+		flusher.Flush()
+	})
+	GetStd(s, "/hijack", func(w http.ResponseWriter, r *http.Request) {
+		hijacker, ok := w.(http.Hijacker)
+		require.True(t, ok)
+		conn, _, err := hijacker.Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked"))
+	})
+
+	t.Run("flusher", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+		require.True(t, w.Flushed)
+		require.Equal(t, "chunk", w.Body.String())
+	})
+
+	t.Run("hijacker", func(t *testing.T) {
+		server := httptest.NewServer(s.Mux)
+		defer server.Close()
+
+		response, err := http.Get(server.URL + "/hijack")
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+		require.Equal(t, "hijacked", string(body))
+	})
+}
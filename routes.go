@@ -0,0 +1,178 @@
+package fuego
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// routeRegistry records the methods registered for each path.
+// The paths are compared with their wildcard names erased, as [http.ServeMux] does: /recipes/{id} and /recipes/{name} are the same path.
+// It is a pointer shared by the server and its groups.
+type routeRegistry struct {
+	mu            sync.RWMutex
+	methods       map[string][]string      // By route key, see [routeKey].
+	optionsRoutes map[string]*optionsRoute // By route key. OPTIONS routes registered by the user or by [HandleOptions].
+}
+
+// optionsRoute is the OPTIONS route of a path.
+type optionsRoute struct {
+	pattern string // Pattern the route is registered with on the mux.
+	user    bool   // Registered by the user, not by [HandleOptions].
+	// Handler of the route. The handler of [HandleOptions] is replaced by the route registered by the user afterwards,
+	// as a pattern cannot be registered twice on the mux.
+	handler atomic.Pointer[http.Handler]
+}
+
+func (route *optionsRoute) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*route.handler.Load()).ServeHTTP(w, r)
+}
+
+func newRouteRegistry() *routeRegistry {
+	return &routeRegistry{
+		methods:       make(map[string][]string),
+		optionsRoutes: make(map[string]*optionsRoute),
+	}
+}
+
+var wildcardRegex = regexp.MustCompile(`{[^}]*}`)
+
+// routeKey returns the path with its wildcard names erased, ex: /recipes/{id}/{path...} becomes /recipes/{}/{...}
+func routeKey(path string) string {
+	return wildcardRegex.ReplaceAllStringFunc(path, func(wildcard string) string {
+		switch {
+		case wildcard == "{$}":
+			return wildcard
+		case strings.HasSuffix(wildcard, "...}"):
+			return "{...}"
+		default:
+			return "{}"
+		}
+	})
+}
+
+func (reg *routeRegistry) add(method, path string) {
+	if method == http.MethodOptions {
+		return
+	}
+	key := routeKey(path)
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if !slices.Contains(reg.methods[key], method) {
+		reg.methods[key] = append(reg.methods[key], method)
+	}
+}
+
+// claimOptionsRoute reports whether the OPTIONS route of [HandleOptions] must be registered for the path,
+// and if so records it with the given handler.
+func (reg *routeRegistry) claimOptionsRoute(path string, handler http.Handler) (*optionsRoute, bool) {
+	key := routeKey(path)
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if reg.optionsRoutes[key] != nil {
+		return nil, false
+	}
+	route := &optionsRoute{pattern: path}
+	route.handler.Store(&handler)
+	reg.optionsRoutes[key] = route
+	return route, true
+}
+
+// replaceOptionsRoute records an OPTIONS route registered by the user. If the path has an OPTIONS route of [HandleOptions],
+// its handler is replaced and true is returned: the route must not be registered on the mux.
+// The path values of the request are renamed after the wildcards of the path.
+func (reg *routeRegistry) replaceOptionsRoute(path string, handler http.Handler) bool {
+	key := routeKey(path)
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	route := reg.optionsRoutes[key]
+	if route == nil {
+		reg.optionsRoutes[key] = &optionsRoute{pattern: path, user: true}
+		return false
+	}
+	if route.user {
+		// Registered twice by the user: the mux panics, as for any other route.
+		return false
+	}
+
+	route.user = true
+	registered, wildcards := parsePathParams(route.pattern), parsePathParams(path)
+	var renamed http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i, wildcard := range wildcards {
+			r.SetPathValue(strings.TrimSuffix(wildcard, "..."), r.PathValue(strings.TrimSuffix(registered[i], "...")))
+		}
+		handler.ServeHTTP(w, r)
+	})
+	route.handler.Store(&renamed)
+	return true
+}
+
+// allowedMethods returns the methods registered for the path, in the usual order, with HEAD for GET and OPTIONS.
+func (reg *routeRegistry) allowedMethods(path string) []string {
+	reg.mu.RLock()
+	registered := reg.methods[routeKey(path)]
+	reg.mu.RUnlock()
+
+	all := slices.Contains(registered, MethodAll)
+	allowed := make([]string, 0, 7)
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		// GET routes also answer HEAD requests, see [http.ServeMux].
+		if all || slices.Contains(registered, method) || method == http.MethodHead && slices.Contains(registered, http.MethodGet) {
+			allowed = append(allowed, method)
+		}
+	}
+	for _, method := range registered {
+		if !slices.Contains(allowed, method) && method != MethodAll {
+			allowed = append(allowed, method)
+		}
+	}
+	return append(allowed, http.MethodOptions)
+}
+
+const contextKeyAllowedMethods contextKey = "allowedMethods"
+
+// AllowedMethods returns the methods registered for the path of the request, in the OPTIONS routes
+// added by [HandleOptions]. Used by CORS middlewares to answer preflight requests. Nil in other routes.
+func AllowedMethods(ctx context.Context) []string {
+	methods, _ := ctx.Value(contextKeyAllowedMethods).(func() []string)
+	if methods == nil {
+		return nil
+	}
+	return methods()
+}
+
+// HandleOptions answers the OPTIONS requests on the paths of the routes registered afterwards on the server or the group,
+// with the methods registered for the path in the Allow header.
+// The middlewares of the routes are run, so a CORS middleware can answer the preflight requests, see [AllowedMethods].
+// The paths with an OPTIONS route registered by the user, before or after, are not changed.
+// Example:
+//
+//	api := fuego.Group(s, "/api")
+//	fuego.Use(api, corsMiddleware)
+//	fuego.HandleOptions(api)
+//	fuego.Get(api, "/recipes", listRecipes) // OPTIONS /api/recipes answers "Allow: GET, HEAD, OPTIONS"
+func HandleOptions(s *Server) {
+	s.optionsRoutes = true
+}
+
+// registerOptionsRoute registers the OPTIONS route of the path, with the given middlewares.
+func (s *Server) registerOptionsRoute(path string, middlewares []func(http.Handler) http.Handler) {
+	allowedMethods := func() []string { return s.routes.allowedMethods(path) }
+	allow := s.wrapRoute(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(allowedMethods(), ", "))
+		w.WriteHeader(http.StatusNoContent)
+	}), middlewares, nil)
+	handler := withRoutePattern(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		allow.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKeyAllowedMethods, allowedMethods)))
+	}), path)
+
+	route, ok := s.routes.claimOptionsRoute(path, handler)
+	if !ok {
+		return
+	}
+	s.Mux.Handle(http.MethodOptions+" "+path, route)
+}
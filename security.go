@@ -16,12 +16,16 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// Authentication errors are sent with a 401 status code, authorization errors with a 403 status code.
+// They can be compared with errors.Is, wrapped with more context, and extracted with errors.As,
+// ex: errors.As(err, &fuego.UnauthorizedError{}).
 var (
-	ErrUnauthorized     = errors.New("unauthorized")
-	ErrTokenNotFound    = errors.New("token not found")
-	ErrInvalidTokenType = errors.New("invalid token type")
-	ErrInvalidRolesType = errors.New("invalid role type. Must be []string")
-	ErrExpired          = errors.New("token is expired")
+	ErrUnauthorized     = UnauthorizedError{Message: "unauthorized"}
+	ErrForbidden        = ForbiddenError{Message: "forbidden"}
+	ErrTokenNotFound    = UnauthorizedError{Message: "token not found"}
+	ErrInvalidTokenType = UnauthorizedError{Message: "invalid token type"}
+	ErrInvalidRolesType = ForbiddenError{Message: "invalid role type. Must be a list of strings"}
+	ErrExpired          = UnauthorizedError{Message: "token is expired"}
 )
 
 // Security holds the key to sign the JWT tokens, and configuration information.
@@ -212,7 +216,11 @@ func (security Security) TokenToContext(searchFunc ...func(*http.Request) string
 			// Validate the token
 			t, err := security.ValidateToken(token)
 			if err != nil {
-				SendJSONError(w, err)
+				var errorStatus ErrorWithStatus
+				if !errors.As(err, &errorStatus) {
+					err = UnauthorizedError{Err: err, Message: "invalid token"}
+				}
+				sendUnauthorized(w, err)
 				return
 			}
 
@@ -259,7 +267,7 @@ func checkRolesRegex(acceptedRolesRegex *regexp.Regexp) func(userRoles ...string
 }
 
 // AuthWall is a middleware that checks if the user is authorized.
-// If not, it returns an error.
+// If not, it returns a 401 error if the user is not authenticated, or a 403 error if the user does not have an authorized role.
 // If authorized roles are provided, the user must have at least one of its role in the list.
 // For example:
 //
@@ -306,20 +314,20 @@ func authWall(authorizeFunc func(userRoles ...string) bool) func(next http.Handl
 			// Get the authorizationHeader from the context (set by TokenToContext)
 			claims, err := TokenFromContext(r.Context())
 			if err != nil {
-				SendJSONError(w, ErrUnauthorized)
+				sendUnauthorized(w, ErrUnauthorized)
 				return
 			}
 
 			// Get the subject and userRoles from the claims
-			userRoles, ok := claims.(jwt.MapClaims)["roles"].([]string)
+			userRoles, ok := claimRoles(claims)
 			if !ok {
-				SendJSONError(w, ErrInvalidTokenType)
+				SendJSONError(w, ErrInvalidRolesType)
 				return
 			}
 
 			// Check if the user is authorized
 			if !authorizeFunc(userRoles...) {
-				SendJSONError(w, ErrUnauthorized)
+				SendJSONError(w, ErrForbidden)
 				return
 			}
 
@@ -329,6 +337,36 @@ func authWall(authorizeFunc func(userRoles ...string) bool) func(next http.Handl
 	}
 }
 
+// claimRoles returns the "roles" claim. Parsed from a JWT, it is a []any holding strings.
+func claimRoles(claims jwt.Claims) ([]string, bool) {
+	mapClaims, ok := claims.(jwt.MapClaims)
+	if !ok {
+		return nil, false
+	}
+	switch roles := mapClaims["roles"].(type) {
+	case []string:
+		return roles, true
+	case []any:
+		userRoles := make([]string, 0, len(roles))
+		for _, role := range roles {
+			role, ok := role.(string)
+			if !ok {
+				return nil, false
+			}
+			userRoles = append(userRoles, role)
+		}
+		return userRoles, true
+	default:
+		return nil, false
+	}
+}
+
+// sendUnauthorized sends a 401 error, with the WWW-Authenticate header required by RFC 9110.
+func sendUnauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	SendJSONError(w, err)
+}
+
 type tokenResponse struct {
 	Token string `json:"token"`
 }
@@ -458,7 +496,7 @@ func (security Security) LoginHandler(verifyUserInfo func(user, password string)
 func (security Security) RefreshHandler(w http.ResponseWriter, r *http.Request) {
 	claims, err := TokenFromContext(r.Context())
 	if err != nil {
-		SendJSONError(w, ErrUnauthorized)
+		sendUnauthorized(w, ErrUnauthorized)
 		return
 	}
 
@@ -171,6 +171,24 @@ func TestAuthWall(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	t.Run("unauthenticated", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+
+		AuthWall("a")(h).ServeHTTP(w, r)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	})
+
+	t.Run("without roles", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), contextKeyJWT, jwt.MapClaims{"sub": "123"}))
+		w := httptest.NewRecorder()
+
+		AuthWall("a")(h).ServeHTTP(w, r)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
 	t.Run("list", func(t *testing.T) {
 		authWall := AuthWall("a", "b")
 		require.NotNil(t, authWall)
@@ -184,7 +202,7 @@ func TestAuthWall(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			authWall(h).ServeHTTP(w, r)
-			require.Equal(t, http.StatusInternalServerError, w.Code)
+			require.Equal(t, http.StatusForbidden, w.Code)
 		})
 
 		t.Run("with token", func(t *testing.T) {
@@ -214,7 +232,7 @@ func TestAuthWall(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			authWall(h).ServeHTTP(w, r)
-			require.Equal(t, http.StatusInternalServerError, w.Code)
+			require.Equal(t, http.StatusForbidden, w.Code)
 		})
 
 		t.Run("with token", func(t *testing.T) {
@@ -230,6 +248,25 @@ func TestAuthWall(t *testing.T) {
 			require.Equal(t, http.StatusOK, w.Code)
 		})
 	})
+
+	t.Run("signed token", func(t *testing.T) {
+		security := NewSecurity()
+		handler := security.TokenToContext(TokenFromHeader)(AuthWall("a")(h))
+		request := func(t *testing.T, roles ...string) *httptest.ResponseRecorder {
+			token, err := security.GenerateToken(jwt.MapClaims{"sub": "123", "roles": roles})
+			require.NoError(t, err)
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			return w
+		}
+
+		require.Equal(t, http.StatusOK, request(t, "a", "d").Code)
+		w := request(t, "c")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.JSONEq(t, `{"error":"forbidden"}`, w.Body.String())
+	})
 }
 
 func TestTokenFromContext(t *testing.T) {
@@ -301,7 +338,8 @@ func TestTokenToContext(t *testing.T) {
 			r.Header.Set("Authorization", "Bearer 123")
 			w := httptest.NewRecorder()
 			tokenToContext(h).ServeHTTP(w, r)
-			require.Equal(t, http.StatusInternalServerError, w.Code)
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+			require.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
 		})
 
 		t.Run("correct token", func(t *testing.T) {
@@ -434,6 +472,7 @@ func TestSecurity_LoginHandler(t *testing.T) {
 
 		cookies := w.Result().Cookies()
 		require.Len(t, cookies, 0)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
 	})
 
 	t.Run("with correct ids", func(t *testing.T) {
@@ -3,12 +3,19 @@ package fuego
 
 import (
 	"context"
+	"encoding"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"mime"
 	"net/http"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 // OutTransformer is an interface for entities that can be transformed.
@@ -91,17 +98,32 @@ func SendJSON(w http.ResponseWriter, ans any) {
 
 // SendJSONError sends a JSON error response.
 // If the error implements ErrorWithStatus, the status code will be set.
+// If the error implements ErrorWithInfo, the info will be sent.
+// A [ProblemDetails] error is sent as application/problem+json.
 func SendJSONError(w http.ResponseWriter, err error) {
-	status := http.StatusInternalServerError
-	errorStatus := HTTPError{
-		Message: err.Error(),
+	var problem ProblemDetails
+	if errors.As(err, &problem) {
+		sendProblemDetails(w, problem, "application/problem+json")
+		return
 	}
+
+	status := http.StatusInternalServerError
+	var errorStatus ErrorWithStatus
 	if errors.As(err, &errorStatus) {
 		status = errorStatus.Status()
 	}
 
+	errorResponse := HTTPError{
+		Message: err.Error(),
+	}
+	var errorInfo ErrorWithInfo
+	if errors.As(err, &errorInfo) {
+		errorResponse.MoreInfo = errorInfo.Info()
+	}
+	errors.As(err, &errorResponse)
+
 	w.WriteHeader(status)
-	SendJSON(w, errorStatus)
+	SendJSON(w, errorResponse)
 }
 
 // SendXML sends a XML response.
@@ -118,13 +140,212 @@ func SendXML(w http.ResponseWriter, ans any) {
 
 // SendXMLError sends a XML error response.
 // If the error implements ErrorWithStatus, the status code will be set.
+// A [ProblemDetails] error is sent as application/problem+xml.
 func SendXMLError(w http.ResponseWriter, err error) {
+	var problem ProblemDetails
+	if errors.As(err, &problem) {
+		sendProblemDetails(w, problem, "application/problem+xml")
+		return
+	}
+
+	status := http.StatusInternalServerError
+	var errorStatus ErrorWithStatus
+	if errors.As(err, &errorStatus) {
+		status = errorStatus.Status()
+	}
+
+	errorResponse := HTTPError{
+		Message: err.Error(),
+	}
+	errors.As(err, &errorResponse)
+
+	w.WriteHeader(status)
+	SendXML(w, errorResponse)
+}
+
+// SendText sends a plain text response.
+// Strings, byte slices, numbers, booleans, [fmt.Stringer] and [encoding.TextMarshaler] values are supported.
+// Other values, like structs, have no text representation: 406 Not Acceptable is sent instead.
+func SendText(w http.ResponseWriter, ans any) {
+	text, ok := formatText(ans)
+	if !ok {
+		SendTextError(w, HTTPError{
+			StatusCode: http.StatusNotAcceptable,
+			Message:    fmt.Sprintf("cannot send %T as text/plain", ans),
+		})
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = io.WriteString(w, text)
+}
+
+// formatText returns the text representation of the value, if it has one. See [SendText].
+func formatText(ans any) (string, bool) {
+	switch v := ans.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		return string(text), err == nil
+	case fmt.Stringer:
+		return v.String(), true
+	}
+
+	value := reflect.ValueOf(ans)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	// A nil interface has no value, so no type.
+	if !value.IsValid() || !hasTextKind(value.Type()) {
+		return "", false
+	}
+	return fmt.Sprint(value.Interface()), true
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// textSerializable reports whether the values of the type can be sent with [SendText].
+// Interfaces may hold such values, so they are considered serializable.
+func textSerializable(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface || t.Implements(textMarshalerType) || t.Implements(stringerType) {
+		return true
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return hasTextKind(t) || t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// hasTextKind reports whether the type is a string, a number or a boolean.
+func hasTextKind(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// SendTextError sends a plain text error response.
+// If the error implements ErrorWithStatus, the status code will be set.
+func SendTextError(w http.ResponseWriter, err error) {
 	status := http.StatusInternalServerError
 	var errorStatus ErrorWithStatus
 	if errors.As(err, &errorStatus) {
 		status = errorStatus.Status()
 	}
 
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 	w.WriteHeader(status)
-	SendXML(w, err)
+	_, _ = w.Write([]byte(err.Error()))
+}
+
+// Serializer holds the functions used to serialize responses for a media type.
+type Serializer struct {
+	Serialize      func(w http.ResponseWriter, ans any)
+	SerializeError func(w http.ResponseWriter, err error)
+}
+
+// acceptedMediaType is a media range of the Accept header, ex: "text/*;q=0.8".
+type acceptedMediaType struct {
+	mediaType string
+	q         float64
+}
+
+// parseAccept parses the Accept header, and sorts the media ranges by preference.
+// Media ranges with the same quality are sorted from the most specific to the least specific, as defined in RFC 9110.
+func parseAccept(header string) []acceptedMediaType {
+	accepted := make([]acceptedMediaType, 0, strings.Count(header, ",")+1)
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || !strings.Contains(mediaType, "/") {
+			continue
+		}
+
+		q := 1.0
+		if qValue, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(qValue, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+
+		accepted = append(accepted, acceptedMediaType{mediaType: mediaType, q: q})
+	}
+
+	slices.SortStableFunc(accepted, func(a, b acceptedMediaType) int {
+		if a.q != b.q {
+			if a.q > b.q {
+				return -1
+			}
+			return 1
+		}
+		return strings.Count(a.mediaType, "*") - strings.Count(b.mediaType, "*")
+	})
+
+	return accepted
+}
+
+// serializableMediaTypes returns the registered media types the values of the type can be serialized to.
+// text/plain is left out for types without text representation, see [SendText].
+func (s *Server) serializableMediaTypes(t reflect.Type) []string {
+	if textSerializable(t) {
+		return s.serializersOrder
+	}
+	mediaTypes := make([]string, 0, len(s.serializersOrder))
+	for _, mediaType := range s.serializersOrder {
+		if mediaType != "text/plain" {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+	}
+	return mediaTypes
+}
+
+// negotiateSerializer chooses the serializer from the Accept header of the request, among the offered media types.
+// If the client accepts any media type, the default [Server.Serialize] and [Server.SerializeError] are used.
+// It returns false if none of the offered media types is acceptable.
+func (s *Server) negotiateSerializer(r *http.Request, offered []string) (Serializer, bool) {
+	defaultSerializer := Serializer{
+		Serialize:      s.Serialize,
+		SerializeError: s.SerializeError,
+	}
+
+	header := r.Header.Get("Accept")
+	if header == "" {
+		return defaultSerializer, true
+	}
+
+	for _, accepted := range parseAccept(header) {
+		if accepted.mediaType == "*/*" {
+			return defaultSerializer, true
+		}
+
+		if slices.Contains(offered, accepted.mediaType) {
+			return s.serializers[accepted.mediaType], true
+		}
+
+		if prefix, isWildcard := strings.CutSuffix(accepted.mediaType, "/*"); isWildcard {
+			for _, mediaType := range offered {
+				if strings.HasPrefix(mediaType, prefix+"/") {
+					return s.serializers[mediaType], true
+				}
+			}
+		}
+	}
+
+	return defaultSerializer, false
 }
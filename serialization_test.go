@@ -2,8 +2,11 @@ package fuego
 
 import (
 	"context"
+	"net"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -60,6 +63,14 @@ func TestXML(t *testing.T) {
 
 		require.Equal(t, `<HTTPError><Error>Hello World</Error></HTTPError>`, body)
 	})
+
+	t.Run("can serialize any error as xml", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendXMLError(w, BadRequestError{Message: "Hello World", MoreInfo: map[string]any{"field": "name"}})
+
+		require.Equal(t, 400, w.Code)
+		require.Equal(t, `<HTTPError><Error>Hello World</Error></HTTPError>`, w.Body.String())
+	})
 }
 
 type tbt struct {
@@ -215,3 +226,130 @@ func TestSend(t *testing.T) {
 
 	require.Equal(t, "Hello World", w.Body.String())
 }
+
+func TestText(t *testing.T) {
+	t.Run("can serialize text", func(t *testing.T) {
+		for _, tc := range []struct {
+			ans  any
+			text string
+		}{
+			{"Hello World", "Hello World"},
+			{42, "42"},
+			{true, "true"},
+			{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), "2024-01-02T00:00:00Z"},
+			{net.IPv4(127, 0, 0, 1), "127.0.0.1"},
+		} {
+			w := httptest.NewRecorder()
+			SendText(w, tc.ans)
+
+			require.Equal(t, 200, w.Code)
+			require.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+			require.Equal(t, tc.text, w.Body.String())
+		}
+	})
+
+	t.Run("cannot serialize structs as text", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendText(w, response{Message: "Hello World", Code: 200})
+
+		require.Equal(t, 406, w.Code)
+		require.Equal(t, "cannot send fuego.response as text/plain", w.Body.String())
+	})
+
+	t.Run("cannot serialize nil as text", func(t *testing.T) {
+		for _, ans := range []any{nil, (*int)(nil)} {
+			w := httptest.NewRecorder()
+			require.NotPanics(t, func() { SendText(w, ans) })
+			require.Equal(t, 406, w.Code)
+		}
+	})
+
+	t.Run("can serialize text error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendTextError(w, HTTPError{Message: "Hello World", StatusCode: 404})
+
+		require.Equal(t, 404, w.Code)
+		require.Equal(t, "Hello World", w.Body.String())
+	})
+}
+
+func TestParseAccept(t *testing.T) {
+	require.Empty(t, parseAccept(""))
+	require.Equal(t, []acceptedMediaType{
+		{mediaType: "application/json", q: 1},
+		{mediaType: "text/html", q: 1},
+		{mediaType: "application/xml", q: 0.9},
+		{mediaType: "text/*", q: 0.8},
+		{mediaType: "*/*", q: 0.8},
+	}, parseAccept("*/*;q=0.8, text/*;q=0.8, application/xml;q=0.9, application/json, text/html, image/png;q=0, invalid;"))
+}
+
+func TestContentNegotiation(t *testing.T) {
+	s := NewServer()
+	Get(s, "/", func(*ContextNoBody) (response, error) {
+		return response{Message: "Hello World", Code: 200}, nil
+	})
+	Get(s, "/error", func(*ContextNoBody) (response, error) {
+		return response{}, HTTPError{Message: "not found", StatusCode: 404}
+	})
+	Get(s, "/string", func(*ContextNoBody) (string, error) {
+		return "hello", nil
+	})
+	Get(s, "/count", func(*ContextNoBody) (int, error) {
+		return 42, nil
+	})
+
+	testCases := []struct {
+		accept      string
+		path        string
+		code        int
+		contentType string
+	}{
+		{accept: "", path: "/", code: 200, contentType: "application/json"},
+		{accept: "*/*", path: "/", code: 200, contentType: "application/json"},
+		{accept: "application/json", path: "/", code: 200, contentType: "application/json"},
+		{accept: "application/xml", path: "/", code: 200, contentType: "application/xml"},
+		{accept: "text/html, application/xml;q=0.9, */*;q=0.8", path: "/", code: 200, contentType: "application/xml"},
+		{accept: "text/*", path: "/", code: 406, contentType: "application/json"},
+		{accept: "text/plain, application/xml;q=0.5", path: "/", code: 200, contentType: "application/xml"},
+		{accept: "text/plain", path: "/count", code: 200, contentType: "text/plain; charset=utf-8"},
+		{accept: "image/png", path: "/", code: 406, contentType: "application/json"},
+		{accept: "application/xml", path: "/error", code: 404, contentType: "application/xml"},
+		{accept: "image/png", path: "/string", code: 200, contentType: "text/plain; charset=utf-8"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.accept+" "+tc.path, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.path, nil)
+			r.Header.Set("Accept", tc.accept)
+			w := httptest.NewRecorder()
+
+			s.Mux.ServeHTTP(w, r)
+
+			require.Equal(t, tc.code, w.Code)
+			require.Equal(t, tc.contentType, w.Header().Get("Content-Type"))
+		})
+	}
+
+	t.Run("custom media type", func(t *testing.T) {
+		s := NewServer(
+			WithMediaTypeSerializer("application/vnd.Custom", func(w http.ResponseWriter, ans any) {
+				w.Header().Set("Content-Type", "application/vnd.custom")
+				_, _ = w.Write([]byte("custom"))
+			}, SendTextError),
+		)
+		Get(s, "/", func(*ContextNoBody) (response, error) {
+			return response{}, nil
+		})
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Accept", "application/vnd.custom")
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, 200, w.Code)
+		require.Equal(t, "custom", w.Body.String())
+		require.Equal(t, "Accept", w.Header().Get("Vary"))
+	})
+}
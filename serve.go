@@ -1,10 +1,13 @@
 package fuego
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"net/http"
+	"os/signal"
 	"reflect"
 	"time"
 )
@@ -12,15 +15,98 @@ import (
 // Run starts the server.
 // It is blocking.
 // It returns an error if the server could not start (it could not bind to the port).
+// The server is shut down gracefully when a shutdown signal is received, see [Server.RunContext].
 func (s *Server) Run() error {
+	return s.RunContext(context.Background())
+}
+
+// RunContext starts the server, and shuts it down gracefully when the context is canceled
+// or when one of the shutdown signals is received (SIGINT and SIGTERM by default, see [WithShutdownSignals]).
+// It is blocking.
+// The hooks registered with [Server.OnStart] run before the server starts listening,
+// and the hooks registered with [Server.OnShutdown] run after the in-flight requests are drained.
+// It returns nil after a graceful shutdown.
+func (s *Server) RunContext(ctx context.Context) error {
+	if len(s.shutdownSignals) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, s.shutdownSignals...)
+		defer stop()
+	}
+
+	err := s.lifecycle.runStartHooks(ctx)
+	if err != nil {
+		return errors.Join(err, s.lifecycle.runShutdownHooks(context.Background()))
+	}
+
+	listenAndServe := s.Server.ListenAndServe
+	if s.usesTLS() {
+		err = s.setupTLS()
+		if err == nil && s.httpRedirectAddr != "" {
+			err = s.startHTTPRedirect(s.httpRedirectAddr)
+		}
+		if err != nil {
+			return errors.Join(err, s.lifecycle.runShutdownHooks(context.Background()))
+		}
+		// The certificates are in the TLS config.
+		listenAndServe = func() error { return s.Server.ListenAndServeTLS("", "") }
+	}
+
 	go s.generateOpenAPI()
 	elapsed := time.Since(s.startTime)
 	slog.Debug("Server started in "+elapsed.String(), "info", "time between since server creation (fuego.NewServer) and server startup (fuego.Run). Depending on your implementation, there might be things that do not depend on fuego slowing start time")
-	slog.Info("Server running ✅ on http://localhost"+s.Server.Addr, "started in", elapsed.String())
+	slog.Info("Server running ✅ on "+s.url(), "started in", elapsed.String())
 
 	s.Server.Handler = s.Mux
 
-	return s.Server.ListenAndServe()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- listenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		// Shut down with [Server.Shutdown]: wait for it to complete.
+		if errors.Is(err, http.ErrServerClosed) {
+			return s.Shutdown(context.Background())
+		}
+		// The server could not start.
+		return errors.Join(err, s.lifecycle.runShutdownHooks(context.Background()))
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down server", "drain timeout", s.shutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	return s.Shutdown(shutdownCtx)
+}
+
+// Shutdown gracefully shuts down the server: it reports not ready during the shutdown delay (see [WithShutdownDelay]),
+// stops accepting new requests, waits for the in-flight requests to complete,
+// then runs the hooks registered with [Server.OnShutdown].
+// If the context expires before, the remaining connections are closed.
+// Only the first call shuts down the server, next calls return the same result.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.lifecycle.shutdownOnce.Do(func() {
+		s.lifecycle.shuttingDown.Store(true)
+
+		if s.shutdownDelay > 0 {
+			slog.Info("Reporting not ready before draining", "delay", s.shutdownDelay.String())
+			select {
+			case <-time.After(s.shutdownDelay):
+			case <-ctx.Done():
+			}
+		}
+
+		err := s.Server.Shutdown(ctx)
+		if err != nil {
+			slog.Warn("Drain timeout exceeded, closing remaining connections", "error", err)
+			err = errors.Join(err, s.Server.Close())
+		}
+
+		s.lifecycle.shutdownErr = errors.Join(err, s.lifecycle.runShutdownHooks(ctx))
+	})
+	return s.lifecycle.shutdownErr
 }
 
 // initializes any Context type with the base ContextNoBody context.
@@ -39,23 +125,66 @@ func initContext[Contextable ctx[Body], Body any](baseContext ContextNoBody) Con
 			ContextNoBody: baseContext,
 		}).(Contextable)
 	default:
+		if initializer, ok := any(c).(interface{ newFromBase(ContextNoBody) any }); ok {
+			return initializer.newFromBase(baseContext).(Contextable)
+		}
 		panic("unknown type")
 	}
 }
 
+// baseContext creates the context of a request, with the server options.
+func (s *Server) baseContext(w http.ResponseWriter, r *http.Request) ContextNoBody {
+	var templates *template.Template
+	if s.template != nil {
+		templates = template.Must(s.template.Clone())
+	}
+
+	return ContextNoBody{
+		request:  r,
+		response: w,
+		readOptions: readOptions{
+			DisallowUnknownFields: s.DisallowUnknownFields,
+			MaxBodySize:           s.maxBodySize,
+			deserializers:         s.deserializers,
+		},
+		fs:        s.fs,
+		templates: templates,
+		logger:    s.logger,
+	}
+}
+
+// handleError transforms the error with the error handler of the server, logs it with the request context,
+// and records it on the span of the request.
+// The instance of a [ProblemDetails] defaults to the path of the request.
+func (s *Server) handleError(r *http.Request, err error) error {
+	err = s.ErrorHandler(err)
+	logError(s.logger, r.Context(), err)
+	recordError(r.Context(), err)
+	if problem, ok := err.(ProblemDetails); ok && problem.Instance == "" {
+		problem.Instance = r.URL.Path
+		return problem
+	}
+	return err
+}
+
 // httpHandler converts a Fuego controller into a http.HandlerFunc.
 func httpHandler[ReturnType any, Body any, Contextable ctx[Body]](s *Server, controller func(c Contextable) (ReturnType, error)) http.HandlerFunc {
-	returnsHTML := reflect.TypeOf(controller).Out(0).Name() == "HTML"
+	returnType := reflect.TypeOf(controller).Out(0)
+	returnsHTML := returnType.Name() == "HTML"
 	var r ReturnType
 	_, returnsString := any(r).(*string)
 	if !returnsString {
 		_, returnsString = any(r).(string)
 	}
+	returnsRenderer := returnType.Implements(reflect.TypeOf((*CtxRenderer)(nil)).Elem()) || returnType.Implements(reflect.TypeOf((*Renderer)(nil)).Elem())
+	// Only serialized responses are negotiated: HTML, strings and renderers are always sent as is.
+	negotiates := !returnsHTML && !returnsString && !returnsRenderer
+	offered := s.serializableMediaTypes(returnType)
 
 	baseContext := *new(Contextable)
 	if reflect.TypeOf(baseContext) == nil {
 		slog.Info(fmt.Sprintf("context is nil: %v %T", baseContext, baseContext))
-		panic("ctx must be provided as concrete type (not interface). ContextNoBody, ContextWithBody[any], ContextFull[any, any], ContextWithQueryParams[any] are supported")
+		panic("ctx must be provided as concrete type (not interface). ContextNoBody, ContextWithBody[any], ContextWithParams[any] are supported")
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -64,36 +193,41 @@ func httpHandler[ReturnType any, Body any, Contextable ctx[Body]](s *Server, con
 		w.Header().Set("Trailer", "Server-Timing")
 		timeCtxInit := time.Now()
 
-		var templates *template.Template
-		if s.template != nil {
-			templates = template.Must(s.template.Clone())
+		serializer := Serializer{Serialize: s.Serialize, SerializeError: s.SerializeError}
+		if negotiates {
+			w.Header().Add("Vary", "Accept")
+			negotiated, acceptable := s.negotiateSerializer(r, offered)
+			if !acceptable {
+				err := s.handleError(r, HTTPError{
+					StatusCode: http.StatusNotAcceptable,
+					Message:    "cannot produce a response matching the Accept header: " + r.Header.Get("Accept"),
+					MoreInfo: map[string]any{
+						"acceptable": offered,
+					},
+				})
+				s.SerializeError(w, err)
+				return
+			}
+			serializer = negotiated
 		}
-		ctx := initContext[Contextable](ContextNoBody{
-			request:  r,
-			response: w,
-			readOptions: readOptions{
-				DisallowUnknownFields: s.DisallowUnknownFields,
-				MaxBodySize:           s.maxBodySize,
-			},
-			fs:        s.fs,
-			templates: templates,
-		})
-
-		// for _, param := range parsePathParams(r.URL.Path) {
-		// 	ctx.pathParams[param] = "coming in go1.22"
-		// }
+
+		// The spans started by the controller are children of the controller span.
+		controllerRequest, endController := startRequestSpan(r, "controller")
+		defer removeMultipartForm(controllerRequest)
+		ctx := initContext[Contextable](s.baseContext(w, controllerRequest))
 
 		timeController := time.Now()
-		w.Header().Set("Server-Timing", Timing{"fuegoReqInit", timeController.Sub(timeCtxInit), ""}.String())
+		addTiming(w, r, Timing{"fuegoReqInit", timeController.Sub(timeCtxInit), ""})
 
 		ans, err := controller(ctx)
+		endController(err)
 		if err != nil {
-			err = s.ErrorHandler(err)
-			s.SerializeError(w, err)
+			err = s.handleError(r, err)
+			serializer.SerializeError(w, err)
 			return
 		}
 		timeAfterController := time.Now()
-		w.Header().Add("Server-Timing", Timing{"controller", timeAfterController.Sub(timeController), ""}.String())
+		addTiming(w, r, Timing{"controller", timeAfterController.Sub(timeController), ""})
 
 		if reflect.TypeOf(ans) == nil {
 			return
@@ -102,42 +236,50 @@ func httpHandler[ReturnType any, Body any, Contextable ctx[Body]](s *Server, con
 		ctxRenderer, ok := any(ans).(CtxRenderer)
 		if ok {
 			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			err = ctxRenderer.Render(r.Context(), w)
+			renderCtx, endRender := startSpan(r.Context(), "render")
+			err = ctxRenderer.Render(renderCtx, w)
+			endRender(err)
 			if err != nil {
-				err = s.ErrorHandler(err)
-				s.SerializeError(w, err)
+				err = s.handleError(r, err)
+				serializer.SerializeError(w, err)
 			}
-			w.Header().Set("Server-Timing", Timing{"render", time.Since(timeAfterController), ""}.String())
+			addTiming(w, r, Timing{"render", time.Since(timeAfterController), ""})
 			return
 		}
 
 		renderer, ok := any(ans).(Renderer)
 		if ok {
 			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, endRender := startSpan(r.Context(), "render")
 			err = renderer.Render(w)
+			endRender(err)
 			if err != nil {
-				err = s.ErrorHandler(err)
-				s.SerializeError(w, err)
+				err = s.handleError(r, err)
+				serializer.SerializeError(w, err)
 			}
-			w.Header().Add("Server-Timing", Timing{"render", time.Since(timeAfterController), ""}.String())
+			addTiming(w, r, Timing{"render", time.Since(timeAfterController), ""})
 			return
 		}
 
 		if returnsHTML {
 			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, endRender := startSpan(r.Context(), "render")
 			_, err = w.Write([]byte(any(ans).(HTML)))
+			endRender(err)
 			if err != nil {
-				s.SerializeError(w, err)
+				serializer.SerializeError(w, err)
 			}
-			w.Header().Add("Server-Timing", Timing{"render", time.Since(timeAfterController), ""}.String())
+			addTiming(w, r, Timing{"render", time.Since(timeAfterController), ""})
 			return
 		}
 
 		timeTransformOut := time.Now()
-		ans, err = transformOut(r.Context(), ans)
+		transformCtx, endTransform := startSpan(r.Context(), "transformOut")
+		ans, err = transformOut(transformCtx, ans)
+		endTransform(err)
 		if err != nil {
-			err = s.ErrorHandler(err)
-			s.SerializeError(w, err)
+			err = s.handleError(r, err)
+			serializer.SerializeError(w, err)
 			return
 		}
 
@@ -147,18 +289,22 @@ func httpHandler[ReturnType any, Body any, Contextable ctx[Body]](s *Server, con
 			if !ok {
 				stringToWrite = *any(ans).(*string)
 			}
+			_, endWrite := startSpan(r.Context(), "write")
 			_, err = w.Write([]byte(stringToWrite))
+			endWrite(err)
 			if err != nil {
-				s.SerializeError(w, err)
+				serializer.SerializeError(w, err)
 			}
-			w.Header().Add("Server-Timing", Timing{"write", time.Since(timeTransformOut), "transformOut"}.String())
+			addTiming(w, r, Timing{"write", time.Since(timeTransformOut), "transformOut"})
 			return
 		}
 
 		timeAfterTransformOut := time.Now()
-		w.Header().Add("Server-Timing", Timing{"transformOut", timeAfterTransformOut.Sub(timeTransformOut), "transformOut"}.String())
+		addTiming(w, r, Timing{"transformOut", timeAfterTransformOut.Sub(timeTransformOut), "transformOut"})
 
-		s.Serialize(w, ans)
-		w.Header().Add("Server-Timing", Timing{"serialize", time.Since(timeAfterTransformOut), ""}.String())
+		_, endSerialize := startSpan(r.Context(), "serialize")
+		serializer.Serialize(w, ans)
+		endSerialize(nil)
+		addTiming(w, r, Timing{"serialize", time.Since(timeAfterTransformOut), ""})
 	}
 }
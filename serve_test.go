@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -168,6 +169,7 @@ func TestServer_Run(t *testing.T) {
 	t.Run("can run server", func(t *testing.T) {
 		s := NewServer(
 			WithoutLogger(),
+			WithOpenapiConfig(OpenapiConfig{DisableLocalSave: true}),
 		)
 
 		Get(s, "/test", func(ctx *ContextNoBody) (string, error) {
@@ -188,6 +190,105 @@ func TestServer_Run(t *testing.T) {
 	})
 }
 
+// freeAddr returns a local address with a port that is free at the time of the call.
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func TestServer_RunContext(t *testing.T) {
+	t.Run("drains in-flight requests and runs hooks", func(t *testing.T) {
+		addr := freeAddr(t)
+		s := NewServer(
+			WithoutLogger(),
+			WithPort(addr),
+			WithShutdownSignals(),
+			WithOpenapiConfig(OpenapiConfig{DisableSwagger: true, DisableLocalSave: true}),
+		)
+
+		var calls []string
+		s.OnStart(func(context.Context) error { calls = append(calls, "start"); return nil })
+		s.OnShutdown(func(context.Context) error { calls = append(calls, "shutdown 1"); return nil })
+		s.OnShutdown(func(context.Context) error { calls = append(calls, "shutdown 2"); return nil })
+
+		requestStarted := make(chan struct{})
+		Get(s, "/slow", func(c *ContextNoBody) (string, error) {
+			close(requestStarted)
+			time.Sleep(50 * time.Millisecond)
+			return "done", nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		runErr := make(chan error, 1)
+		go func() { runErr <- s.RunContext(ctx) }()
+
+		require.Eventually(t, func() bool {
+			conn, err := net.Dial("tcp", addr)
+			if err == nil {
+				conn.Close()
+			}
+			return err == nil
+		}, time.Second, 5*time.Millisecond)
+
+		responseBody := make(chan string, 1)
+		go func() {
+			resp, err := http.Get("http://" + addr + "/slow")
+			if err != nil {
+				responseBody <- err.Error()
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := io.ReadAll(resp.Body)
+			responseBody <- string(body)
+		}()
+
+		<-requestStarted
+		cancel()
+
+		require.Equal(t, "done", <-responseBody, "in-flight request completes")
+		require.NoError(t, <-runErr)
+		require.True(t, s.ShuttingDown())
+		require.Equal(t, []string{"start", "shutdown 2", "shutdown 1"}, calls)
+	})
+
+	t.Run("does not start if a start hook fails", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithPort(freeAddr(t)),
+			WithShutdownSignals(),
+		)
+		shutdownHookCalled := false
+		s.OnStart(func(context.Context) error { return errors.New("database unreachable") })
+		s.OnShutdown(func(context.Context) error { shutdownHookCalled = true; return nil })
+
+		err := s.RunContext(context.Background())
+		require.ErrorContains(t, err, "database unreachable")
+		require.True(t, shutdownHookCalled)
+	})
+
+	t.Run("shutdown from another goroutine", func(t *testing.T) {
+		s := NewServer(
+			WithoutLogger(),
+			WithPort(freeAddr(t)),
+			WithShutdownSignals(),
+			WithOpenapiConfig(OpenapiConfig{DisableSwagger: true, DisableLocalSave: true}),
+		)
+		hookErr := errors.New("cannot close database")
+		s.OnShutdown(func(context.Context) error { return hookErr })
+
+		runErr := make(chan error, 1)
+		go func() { runErr <- s.RunContext(context.Background()) }()
+		time.Sleep(10 * time.Millisecond)
+
+		require.ErrorIs(t, s.Shutdown(context.Background()), hookErr)
+		require.ErrorIs(t, <-runErr, hookErr)
+		require.ErrorIs(t, s.Shutdown(context.Background()), hookErr, "next calls return the same result")
+	})
+}
+
 func TestSetStatusBeforeSend(t *testing.T) {
 	s := NewServer()
 
@@ -0,0 +1,183 @@
+package fuego
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Event is a Server-Sent Event, as defined in https://html.spec.whatwg.org/multipage/server-sent-events.html
+type Event[T any] struct {
+	ID    string        // Sent as the id field. The client sends it back in the Last-Event-ID header when reconnecting. Must not contain line breaks.
+	Name  string        // Sent as the event field. If empty, the client receives a "message" event. Must not contain line breaks.
+	Data  T             // Serialized as JSON, except strings that are sent as is, one data field per line.
+	Retry time.Duration // Reconnection time the client should wait before reconnecting. Not sent if 0.
+}
+
+// newlines normalizes the line breaks of the data: CR, LF and CRLF all end a line in the text/event-stream format.
+var newlines = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// write writes the event in the text/event-stream format.
+// IDs and names with line breaks are rejected, as they would inject fields or events in the stream.
+func (e Event[T]) write(w http.ResponseWriter) error {
+	if strings.ContainsAny(e.ID, "\r\n") {
+		return fmt.Errorf("SSE: event ID %q must not contain line breaks", e.ID)
+	}
+	if strings.ContainsAny(e.Name, "\r\n") {
+		return fmt.Errorf("SSE: event name %q must not contain line breaks", e.Name)
+	}
+
+	var sb strings.Builder
+	if e.ID != "" {
+		sb.WriteString("id: " + e.ID + "\n")
+	}
+	if e.Name != "" {
+		sb.WriteString("event: " + e.Name + "\n")
+	}
+	if e.Retry > 0 {
+		sb.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	data, isString := any(e.Data).(string)
+	if !isString {
+		jsonData, err := json.Marshal(e.Data)
+		if err != nil {
+			return err
+		}
+		data = string(jsonData)
+	}
+	for _, line := range strings.Split(newlines.Replace(data), "\n") {
+		sb.WriteString("data: " + line + "\n")
+	}
+	sb.WriteString("\n")
+
+	_, err := w.Write([]byte(sb.String()))
+	return err
+}
+
+// SSE registers a Server-Sent Events controller on a GET route.
+// The controller sends events with the send function until it returns, or until the client disconnects:
+// then, send returns an error and the request context is canceled.
+// Comments are sent regularly to keep the connection alive, see [WithSSEHeartbeat].
+// If the controller returns an error, it is sent to the client as an "error" event before closing the stream.
+// Example:
+//
+//	fuego.SSE(s, "/orders/live", func(c fuego.ContextNoBody, send func(fuego.Event[Order]) error) error {
+//		orders := ordersService.Subscribe(c.Context(), c.LastEventID())
+//		for order := range orders {
+//			err := send(fuego.Event[Order]{ID: order.ID, Data: order})
+//			if err != nil {
+//				return err
+//			}
+//		}
+//		return nil
+//	})
+func SSE[T any](s *Server, path string, controller func(c ContextNoBody, send func(Event[T]) error) error, middlewares ...func(http.Handler) http.Handler) Route[T, any] {
+	fullPath := http.MethodGet + " " + path
+	slog.Debug("registering SSE controller " + fullPath)
+
+	route := register[T, any](s, http.MethodGet, path, sseHandler(s, controller), middlewares...)
+
+	name, nameWithPath := funcName(controller)
+	route.operation.Summary = name
+	route.operation.Description = "controller: " + nameWithPath
+	route.operation.OperationID = fullPath + ":" + name
+
+	response := route.operation.Responses.Value("200")
+	if response != nil && response.Value != nil {
+		var dataSchema *openapi3.SchemaRef
+		for _, mediaType := range response.Value.Content {
+			dataSchema = mediaType.Schema
+			break
+		}
+		response.Value.WithDescription("Stream of Server-Sent Events. The data of each event is described by the schema.")
+		response.Value.Content = openapi3.Content{
+			"text/event-stream": openapi3.NewMediaType().WithSchemaRef(dataSchema),
+		}
+	}
+
+	return route
+}
+
+// sseHandler converts a SSE controller into a http.HandlerFunc.
+func sseHandler[T any](s *Server, controller func(c ContextNoBody, send func(Event[T]) error) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Powered-By", "Fuego")
+
+		responseController := http.NewResponseController(w)
+		// The stream lasts longer than the server write timeout.
+		_ = responseController.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no") // Disables proxy buffering (nginx)
+		w.WriteHeader(http.StatusOK)
+		err := responseController.Flush()
+		if err != nil {
+			slog.Error("SSE: streaming is not supported by the response writer", "error", err)
+			return
+		}
+
+		ctx := s.baseContext(w, r)
+
+		// Events and heartbeats are written from different goroutines.
+		var mu sync.Mutex
+		write := func(writeFunc func() error) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if err := r.Context().Err(); err != nil {
+				return err
+			}
+			if err := writeFunc(); err != nil {
+				return err
+			}
+			return responseController.Flush()
+		}
+
+		send := func(event Event[T]) error {
+			return write(func() error { return event.write(w) })
+		}
+
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		if s.sseHeartbeat > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(s.sseHeartbeat)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-done:
+						return
+					case <-r.Context().Done():
+						return
+					case <-ticker.C:
+						_ = write(func() error {
+							_, err := w.Write([]byte(": heartbeat\n\n"))
+							return err
+						})
+					}
+				}
+			}()
+		}
+
+		err = controller(ctx, send)
+		close(done)
+		wg.Wait()
+
+		// If the client is gone, there is nobody to send the error to.
+		if err != nil && r.Context().Err() == nil {
+			err = s.handleError(r, err)
+			_ = write(func() error { return Event[error]{Name: "error", Data: err}.write(w) })
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type sseData struct {
+	Count int `json:"count"`
+}
+
+func TestSSE(t *testing.T) {
+	t.Run("can stream events", func(t *testing.T) {
+		s := NewServer()
+		SSE(s, "/events", func(c ContextNoBody, send func(Event[sseData]) error) error {
+			require.Equal(t, "41", c.LastEventID())
+			for i := 42; i < 44; i++ {
+				err := send(Event[sseData]{ID: strconv.Itoa(i), Name: "count", Data: sseData{Count: i}})
+				if err != nil {
+					return err
+				}
+			}
+			return send(Event[sseData]{Retry: time.Second, Data: sseData{Count: 44}})
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/events", nil)
+		r.Header.Set("Last-Event-ID", "41")
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+		require.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+		require.Equal(t, "id: 42\nevent: count\ndata: {\"count\":42}\n\n"+
+			"id: 43\nevent: count\ndata: {\"count\":43}\n\n"+
+			"retry: 1000\ndata: {\"count\":44}\n\n", w.Body.String())
+	})
+
+	t.Run("can stream multiline strings", func(t *testing.T) {
+		s := NewServer()
+		SSE(s, "/events", func(c ContextNoBody, send func(Event[string]) error) error {
+			return send(Event[string]{Data: "hello\nworld\r\nfrom\rfuego"})
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/events", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, "data: hello\ndata: world\ndata: from\ndata: fuego\n\n", w.Body.String())
+	})
+
+	t.Run("rejects line breaks in IDs and names", func(t *testing.T) {
+		s := NewServer()
+		SSE(s, "/events", func(c ContextNoBody, send func(Event[string]) error) error {
+			require.Error(t, send(Event[string]{ID: "1\nevent: admin", Data: "injected"}))
+			require.Error(t, send(Event[string]{Name: "message\r\ndata: injected", Data: "injected"}))
+			return send(Event[string]{ID: "2", Data: "safe"})
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/events", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, "id: 2\ndata: safe\n\n", w.Body.String())
+	})
+
+	t.Run("sends controller error as an error event", func(t *testing.T) {
+		s := NewServer()
+		SSE(s, "/events", func(c ContextNoBody, send func(Event[sseData]) error) error {
+			return HTTPError{Message: "stream failed", StatusCode: http.StatusServiceUnavailable}
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/events", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Equal(t, "event: error\ndata: {\"error\":\"stream failed\"}\n\n", w.Body.String())
+	})
+
+	t.Run("sends heartbeats", func(t *testing.T) {
+		s := NewServer(WithSSEHeartbeat(time.Millisecond))
+		SSE(s, "/events", func(c ContextNoBody, send func(Event[sseData]) error) error {
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		})
+
+		r := httptest.NewRequest(http.MethodGet, "/events", nil)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.Contains(t, w.Body.String(), ": heartbeat\n\n")
+	})
+
+	t.Run("stops when the client disconnects", func(t *testing.T) {
+		s := NewServer()
+		SSE(s, "/events", func(c ContextNoBody, send func(Event[sseData]) error) error {
+			for i := 0; ; i++ {
+				err := send(Event[sseData]{Data: sseData{Count: i}})
+				if err != nil {
+					return err
+				}
+				time.Sleep(time.Millisecond)
+			}
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		r := httptest.NewRequest(http.MethodGet, "/events", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		s.Mux.ServeHTTP(w, r)
+
+		require.True(t, errors.Is(ctx.Err(), context.DeadlineExceeded))
+		require.NotContains(t, w.Body.String(), "event: error")
+	})
+
+	t.Run("is documented as text/event-stream", func(t *testing.T) {
+		s := NewServer()
+		route := SSE(s, "/events", func(c ContextNoBody, send func(Event[sseData]) error) error {
+			return nil
+		})
+
+		content := route.operation.Responses.Value("200").Value.Content
+		require.Len(t, content, 1)
+		require.Equal(t, "#/components/schemas/sseData", content["text/event-stream"].Schema.Ref)
+	})
+}
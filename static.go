@@ -0,0 +1,299 @@
+package fuego
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-fuego/fuego/internal/httpheader"
+)
+
+// StaticOptions are the options of [Static].
+type StaticOptions struct {
+	// Cache-Control max-age of the files requested without fingerprint.
+	// Defaults to 0: browsers revalidate them with their ETag before each use.
+	// Fingerprinted files are always cached for a year, as their URL changes with their content.
+	MaxAge time.Duration
+}
+
+// fingerprintLength is the number of hexadecimal characters of the content hash inserted in the asset URLs.
+const fingerprintLength = 12
+
+// precompressedEncodings are the encodings of the precompressed siblings, by order of preference, with their file extension.
+var precompressedEncodings = []struct{ encoding, extension string }{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// Static serves the files of the file system under the prefix, for example to serve an embedded directory:
+//
+//	//go:embed static
+//	var staticFS embed.FS
+//	...
+//	assets, _ := fs.Sub(staticFS, "static")
+//	fuego.Static(s, "/static", assets, fuego.StaticOptions{})
+//
+// The files are served with a strong ETag computed from their content, and support conditional and Range requests.
+// Directories are not listed.
+//
+// The URLs returned by [Server.AssetURL], or by the "asset" function of the templates, include a fingerprint of the content:
+// /static/css/app.css becomes /static/css/app.3f2a9c1b2d4e.css. Fingerprinted URLs are cached for a year by browsers,
+// and change when the file changes. Files already fingerprinted by a bundler (ex: app-3f2a9c1b.js) are cached for a year too.
+//
+// When the client accepts it, a precompressed sibling of the file (app.css.br or app.css.gz) is served instead of the file.
+func Static(s *Server, prefix string, fsys fs.FS, options StaticOptions, middlewares ...func(http.Handler) http.Handler) Route[any, any] {
+	prefix = strings.TrimSuffix(prefix, "/")
+	files := &staticFiles{
+		fsys:    fsys,
+		prefix:  s.basePath + prefix,
+		options: options,
+		hashes:  make(map[string]fileHash),
+	}
+	s.assets.add(files)
+
+	return GetStd(s, prefix+"/{path...}", files.serve, middlewares...).
+		SetTags("Static").
+		WithSummary("Static files")
+}
+
+// AssetURL returns the fingerprinted URL of a file served with [Static], from its URL without fingerprint.
+// The URL is returned unchanged if it is not the one of a file served with [Static].
+// In the templates, the "asset" function does the same:
+//
+//	<link rel="stylesheet" href="{{ asset "/static/css/app.css" }}">
+//
+// Templates set with [WithTemplates] must declare the function before being parsed,
+// for example with template.New("").Funcs(template.FuncMap{"asset": func(string) string { return "" }}).
+func (s *Server) AssetURL(urlPath string) string {
+	return s.assets.url(urlPath)
+}
+
+// staticAssets records the file systems served with [Static], to compute the URLs of their files.
+// It is a pointer shared by the server and its groups.
+type staticAssets struct {
+	mu    sync.RWMutex
+	files []*staticFiles
+}
+
+func newStaticAssets() *staticAssets {
+	return &staticAssets{}
+}
+
+func (a *staticAssets) add(files *staticFiles) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.files = append(a.files, files)
+}
+
+// url returns the fingerprinted URL of the asset, or the URL unchanged if it is not served.
+func (a *staticAssets) url(urlPath string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, files := range a.files {
+		name, ok := strings.CutPrefix(urlPath, files.prefix+"/")
+		if !ok {
+			continue
+		}
+		file, err := files.stat(name)
+		if err != nil {
+			continue
+		}
+		return files.prefix + "/" + fingerprint(name, file.hash[:fingerprintLength])
+	}
+	return urlPath
+}
+
+// templateFuncs are the functions available in the templates.
+func (a *staticAssets) templateFuncs() template.FuncMap {
+	return template.FuncMap{"asset": a.url}
+}
+
+// staticFiles serves a file system registered with [Static].
+type staticFiles struct {
+	fsys    fs.FS
+	prefix  string // URL prefix of the files, with the base path of the server.
+	options StaticOptions
+
+	mu     sync.Mutex
+	hashes map[string]fileHash // By file name.
+}
+
+// fileHash is the content hash of a file, valid as long as its modification time and size do not change.
+type fileHash struct {
+	modTime time.Time
+	size    int64
+	hash    string // Hexadecimal SHA-256 of the content.
+}
+
+// stat returns the hash of the file, computed once. Directories are not found.
+func (f *staticFiles) stat(name string) (fileHash, error) {
+	info, err := fs.Stat(f.fsys, name)
+	if err != nil {
+		return fileHash{}, err
+	}
+	if info.IsDir() {
+		return fileHash{}, fs.ErrNotExist
+	}
+
+	f.mu.Lock()
+	cached, ok := f.hashes[name]
+	f.mu.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached, nil
+	}
+
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return fileHash{}, err
+	}
+	defer file.Close()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, file); err != nil {
+		return fileHash{}, err
+	}
+
+	cached = fileHash{modTime: info.ModTime(), size: info.Size(), hash: hex.EncodeToString(sum.Sum(nil))}
+	f.mu.Lock()
+	f.hashes[name] = cached
+	f.mu.Unlock()
+	return cached, nil
+}
+
+func (f *staticFiles) serve(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("path")
+	file, err := f.stat(name)
+	immutable := fingerprintedByBundler(name)
+	if err != nil {
+		original, hash, ok := parseFingerprint(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		file, err = f.stat(original)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		// With an outdated fingerprint, the current content is served, but not cached for long.
+		name, immutable = original, strings.HasPrefix(file.hash, hash)
+	}
+
+	switch {
+	case immutable:
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	case f.options.MaxAge > 0:
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(f.options.MaxAge.Seconds())))
+	default:
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+
+	// The precompressed siblings are not used for Range requests, as the ranges apply to the content served.
+	served := name
+	var offered []string
+	siblings := map[string]string{} // By encoding.
+	for _, precompressed := range precompressedEncodings {
+		if _, err := fs.Stat(f.fsys, name+precompressed.extension); err == nil {
+			offered = append(offered, precompressed.encoding)
+			siblings[precompressed.encoding] = name + precompressed.extension
+		}
+	}
+	if len(offered) > 0 {
+		httpheader.AddVary(w.Header(), "Accept-Encoding")
+		encoding := httpheader.NegotiateEncoding(r.Header.Get("Accept-Encoding"), offered)
+		if encoding != "" && r.Header.Get("Range") == "" {
+			sibling, err := f.stat(siblings[encoding])
+			if err != nil {
+				http.NotFound(w, r)
+				return
+			}
+			served, file = siblings[encoding], sibling
+			w.Header().Set("Content-Encoding", encoding)
+			if contentType == "" {
+				// Sniffed by net/http otherwise, but from the compressed content.
+				contentType = "application/octet-stream"
+			}
+		}
+	}
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("ETag", `"`+file.hash+`"`)
+
+	content, err := f.open(served)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer content.Close()
+
+	// Handles the conditional requests with the ETag, the Range requests and the HEAD requests.
+	http.ServeContent(w, r, name, file.modTime, content)
+}
+
+// readSeekCloser is implemented by the files of most file systems, including [embed.FS] and [os.DirFS].
+type readSeekCloser interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// open opens the file, reading it in memory if it cannot seek.
+func (f *staticFiles) open(name string) (readSeekCloser, error) {
+	file, err := f.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if seeker, ok := file.(readSeekCloser); ok {
+		return seeker, nil
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	return nopCloser{bytes.NewReader(content)}, nil
+}
+
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error { return nil }
+
+// fingerprint inserts the hash before the extension of the file name: css/app.css becomes css/app.<hash>.css
+func fingerprint(name, hash string) string {
+	extension := path.Ext(name)
+	return strings.TrimSuffix(name, extension) + "." + hash + extension
+}
+
+var fingerprinted = regexp.MustCompile(`^(.+)\.([0-9a-f]{` + strconv.Itoa(fingerprintLength) + `})(\.[^./]+)?$`)
+
+// parseFingerprint returns the original file name and the hash of a name returned by [fingerprint].
+func parseFingerprint(name string) (original, hash string, ok bool) {
+	matches := fingerprinted.FindStringSubmatch(name)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1] + matches[3], matches[2], true
+}
+
+var bundlerFingerprint = regexp.MustCompile(`[.-]([0-9a-f]{8,})\.[^./]+$`)
+
+// fingerprintedByBundler reports whether the file name includes a hash added by a bundler, ex: app-3f2a9c1b.js or chunk.3f2a9c1b2d4e.css.
+// Hashes have at least one digit, to avoid matching words like "deadbeef".
+func fingerprintedByBundler(name string) bool {
+	matches := bundlerFingerprint.FindStringSubmatch(name)
+	return matches != nil && strings.ContainsAny(matches[1], "0123456789")
+}
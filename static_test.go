@@ -0,0 +1,184 @@
+package fuego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatic(t *testing.T) {
+	css := "body { color: red; }"
+	assets := fstest.MapFS{
+		"css/app.css":          {Data: []byte(css)},
+		"css/app.css.br":       {Data: []byte("brotli")},
+		"css/app.css.gz":       {Data: []byte("gzip")},
+		"js/chunk-3f2a9c1b.js": {Data: []byte("console.log('chunk')")},
+		"robots.txt":           {Data: []byte("User-agent: *")},
+	}
+	s := NewServer(WithBasePath("/app"))
+	Static(s, "/static/", assets, StaticOptions{})
+	Static(Group(s, "/cdn"), "/files", assets, StaticOptions{MaxAge: time.Hour})
+
+	get := func(path string, headers ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		for i := 0; i < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("serves the files with a strong ETag", func(t *testing.T) {
+		w := get("/app/static/css/app.css")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, css, w.Body.String())
+		require.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+		require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+		etag := w.Header().Get("ETag")
+		require.Regexp(t, `^"[0-9a-f]{64}"$`, etag)
+
+		w = get("/app/static/css/app.css", "If-None-Match", etag)
+		require.Equal(t, http.StatusNotModified, w.Code)
+		require.Empty(t, w.Body.String())
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		w := get("/app/cdn/files/robots.txt")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+		require.Empty(t, w.Header().Get("Vary"))
+	})
+
+	t.Run("fingerprinted URLs", func(t *testing.T) {
+		url := s.AssetURL("/app/static/css/app.css")
+		require.Regexp(t, `^/app/static/css/app\.[0-9a-f]{12}\.css$`, url)
+		require.Regexp(t, `^/app/cdn/files/robots\.[0-9a-f]{12}\.txt$`, s.AssetURL("/app/cdn/files/robots.txt"))
+		require.Equal(t, "/app/static/missing.css", s.AssetURL("/app/static/missing.css"))
+		require.Equal(t, "/other/app.css", s.AssetURL("/other/app.css"))
+
+		w := get(url)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, css, w.Body.String())
+		require.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+		require.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+
+		w = get("/app/static/css/app.000000000000.css")
+		require.Equal(t, http.StatusOK, w.Code, "outdated fingerprints serve the current content")
+		require.Equal(t, "no-cache", w.Header().Get("Cache-Control"))
+
+		w = get("/app/static/js/chunk-3f2a9c1b.js")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "public, max-age=31536000, immutable", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("range requests", func(t *testing.T) {
+		w := get("/app/static/css/app.css", "Range", "bytes=0-3", "Accept-Encoding", "br")
+		require.Equal(t, http.StatusPartialContent, w.Code)
+		require.Equal(t, "body", w.Body.String())
+		require.Empty(t, w.Header().Get("Content-Encoding"))
+		require.Equal(t, "bytes 0-3/20", w.Header().Get("Content-Range"))
+	})
+
+	t.Run("precompressed siblings", func(t *testing.T) {
+		for acceptEncoding, encoding := range map[string]string{
+			"gzip, br":          "br",
+			"gzip":              "gzip",
+			"br;q=0.5, gzip":    "gzip",
+			"deflate":           "",
+			"br;q=0, gzip;q=0":  "",
+			"*":                 "br",
+			"identity, *;q=0.1": "br",
+		} {
+			w := get("/app/static/css/app.css", "Accept-Encoding", acceptEncoding)
+			require.Equal(t, http.StatusOK, w.Code)
+			require.Equal(t, encoding, w.Header().Get("Content-Encoding"), acceptEncoding)
+			require.Equal(t, "text/css; charset=utf-8", w.Header().Get("Content-Type"))
+			require.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+			switch encoding {
+			case "br":
+				require.Equal(t, "brotli", w.Body.String())
+			case "gzip":
+				require.Equal(t, "gzip", w.Body.String())
+			default:
+				require.Equal(t, css, w.Body.String())
+			}
+		}
+
+		identity := get("/app/static/css/app.css").Header().Get("ETag")
+		compressed := get("/app/static/css/app.css", "Accept-Encoding", "br").Header().Get("ETag")
+		require.NotEqual(t, identity, compressed, "each encoding has its own ETag")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		for _, path := range []string{"/app/static/missing.css", "/app/static/css", "/app/static/css/", "/app/static/", "/app/static/css/missing.0123456789ab.css"} {
+			w := get(path)
+			require.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+	})
+
+	t.Run("HEAD requests", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodHead, "/app/static/robots.txt", nil)
+		w := httptest.NewRecorder()
+		s.Mux.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, "13", w.Header().Get("Content-Length"))
+		require.Empty(t, w.Body.String())
+	})
+}
+
+func TestAssetTemplateFunction(t *testing.T) {
+	templates := fstest.MapFS{
+		"page.html": {Data: []byte(`<link rel="stylesheet" href="{{ asset "/static/app.css" }}">`)},
+	}
+	s := NewServer(
+		WithTemplateFS(templates),
+		WithTemplateGlobs("*.html"),
+	)
+	Static(s, "/static", fstest.MapFS{"app.css": {Data: []byte("body {}")}}, StaticOptions{})
+	Get(s, "/", func(c ContextNoBody) (HTML, error) {
+		return c.Render("page.html", nil)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Regexp(t, `^<link rel="stylesheet" href="/static/app\.[0-9a-f]{12}\.css">$`, w.Body.String())
+
+	url := strings.TrimSuffix(strings.TrimPrefix(w.Body.String(), `<link rel="stylesheet" href="`), `">`)
+	w = httptest.NewRecorder()
+	s.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "body {}", w.Body.String())
+}
+
+func TestFingerprint(t *testing.T) {
+	require.Equal(t, "css/app.0123456789ab.css", fingerprint("css/app.css", "0123456789ab"))
+	require.Equal(t, "v1.2/LICENSE.0123456789ab", fingerprint("v1.2/LICENSE", "0123456789ab"))
+
+	original, hash, ok := parseFingerprint("css/app.min.0123456789ab.css")
+	require.True(t, ok)
+	require.Equal(t, "css/app.min.css", original)
+	require.Equal(t, "0123456789ab", hash)
+
+	original, _, ok = parseFingerprint("v1.2/LICENSE.0123456789ab")
+	require.True(t, ok)
+	require.Equal(t, "v1.2/LICENSE", original)
+
+	_, _, ok = parseFingerprint("css/app.css")
+	require.False(t, ok)
+
+	require.True(t, fingerprintedByBundler("assets/index-4b1e9f0a.js"))
+	require.True(t, fingerprintedByBundler("main.3f2a9c1b2d4e.css"))
+	require.False(t, fingerprintedByBundler("deadbeef.css"), "no separator")
+	require.False(t, fingerprintedByBundler("app.deadbeefcafe.css"), "no digit")
+	require.False(t, fingerprintedByBundler("jquery-3.7.1.js"))
+}
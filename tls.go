@@ -0,0 +1,188 @@
+package fuego
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader loads a TLS certificate from files, and reloads it when the files change on disk,
+// so renewed certificates (ex: by certbot or cert-manager) are served without restarting the server.
+type certReloader struct {
+	certFile      string
+	keyFile       string
+	checkInterval time.Duration // Minimum time between two checks of the files modification time.
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time // Latest modification time of the certificate and key files.
+	lastCheck time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	reloader := &certReloader{
+		certFile:      certFile,
+		keyFile:       keyFile,
+		checkInterval: 10 * time.Second,
+	}
+
+	modTime, err := reloader.filesModTime()
+	if err != nil {
+		return nil, err
+	}
+	err = reloader.load(modTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// filesModTime returns the latest modification time of the certificate and key files.
+func (r *certReloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot read TLS file: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("cannot load TLS certificate: %w", err)
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// GetCertificate is used as [tls.Config.GetCertificate].
+// If the files changed since the last load, the certificate is reloaded.
+// If the new files are invalid (ex: the certificate is written but not the key yet), the previous certificate is kept.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) < r.checkInterval {
+		return r.cert, nil
+	}
+	r.lastCheck = time.Now()
+
+	modTime, err := r.filesModTime()
+	if err != nil {
+		slog.Error("Cannot check TLS certificate, keeping the previous one", "error", err)
+		return r.cert, nil
+	}
+	if modTime.Equal(r.modTime) {
+		return r.cert, nil
+	}
+
+	err = r.load(modTime)
+	if err != nil {
+		slog.Error("Cannot reload TLS certificate, keeping the previous one", "error", err)
+		return r.cert, nil
+	}
+	slog.Info("TLS certificate reloaded", "cert", r.certFile)
+
+	return r.cert, nil
+}
+
+// usesTLS reports whether the server is configured to serve HTTPS.
+func (s *Server) usesTLS() bool {
+	return s.tlsCertFile != "" || s.tlsConfig != nil
+}
+
+// setupTLS sets the TLS configuration of the underlying [http.Server], from the TLS options.
+func (s *Server) setupTLS() error {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if s.tlsConfig != nil {
+		config = s.tlsConfig.Clone()
+	}
+
+	if s.tlsCertFile != "" {
+		reloader, err := newCertReloader(s.tlsCertFile, s.tlsKeyFile)
+		if err != nil {
+			return err
+		}
+		config.GetCertificate = reloader.GetCertificate
+	}
+
+	if s.disableHTTP2 {
+		// A non-nil empty map disables HTTP/2, see [http.Server.TLSNextProto].
+		s.Server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
+	s.Server.TLSConfig = config
+	return nil
+}
+
+// startHTTPRedirect starts a plain HTTP server on the given address, that redirects all requests to HTTPS.
+// It is shut down with the server.
+func (s *Server) startHTTPRedirect(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot start HTTP to HTTPS redirect: %w", err)
+	}
+
+	_, httpsPort, _ := net.SplitHostPort(s.Server.Addr)
+	redirectServer := &http.Server{
+		Handler:           httpsRedirectHandler(httpsPort),
+		ReadHeaderTimeout: s.Server.ReadHeaderTimeout,
+	}
+	s.OnShutdown(redirectServer.Shutdown)
+
+	go func() {
+		err := redirectServer.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			slog.Error("HTTP to HTTPS redirect stopped", "error", err)
+		}
+	}()
+	slog.Info("Redirecting HTTP to HTTPS", "addr", addr)
+
+	return nil
+}
+
+// httpsRedirectHandler permanently redirects the requests to the same URL with the https scheme and the given port.
+func httpsRedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
+
+// url returns the URL the server is reachable at, for the logs.
+func (s *Server) url() string {
+	scheme := "http"
+	if s.usesTLS() {
+		scheme = "https"
+	}
+
+	host, port, err := net.SplitHostPort(s.Server.Addr)
+	if err != nil {
+		return scheme + "://" + s.Server.Addr
+	}
+	if host == "" {
+		host = "localhost"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
@@ -0,0 +1,180 @@
+package fuego
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestCertificate writes a self-signed certificate for localhost and its key in the directory.
+func writeTestCertificate(t *testing.T, dir, commonName string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o600))
+	return certFile, keyFile
+}
+
+func certificateCommonName(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCertificate(t, dir, "first")
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	require.NoError(t, err)
+	reloader.checkInterval = 0
+
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "first", certificateCommonName(t, cert))
+
+	t.Run("reloads the certificate when the files change", func(t *testing.T) {
+		writeTestCertificate(t, dir, "second")
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(certFile, future, future))
+
+		cert, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+		require.Equal(t, "second", certificateCommonName(t, cert))
+	})
+
+	t.Run("keeps the previous certificate if the files are invalid", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0o600))
+		future := time.Now().Add(2 * time.Minute)
+		require.NoError(t, os.Chtimes(keyFile, future, future))
+
+		cert, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+		require.Equal(t, "second", certificateCommonName(t, cert))
+	})
+
+	t.Run("cannot start with missing files", func(t *testing.T) {
+		_, err := newCertReloader(filepath.Join(dir, "missing.pem"), keyFile)
+		require.Error(t, err)
+	})
+}
+
+func TestServer_RunContext_TLS(t *testing.T) {
+	certFile, keyFile := writeTestCertificate(t, t.TempDir(), "fuego")
+	addr := freeAddr(t)
+	redirectAddr := freeAddr(t)
+
+	s := NewServer(
+		WithoutLogger(),
+		WithPort(addr),
+		WithTLS(certFile, keyFile),
+		WithHTTPRedirect(redirectAddr),
+		WithShutdownSignals(),
+		WithOpenapiConfig(OpenapiConfig{DisableSwagger: true, DisableLocalSave: true}),
+	)
+	Get(s, "/test", func(c *ContextNoBody) (string, error) {
+		return c.Request().Proto, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- s.RunContext(ctx) }()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true}, // #nosec G402 (self-signed test certificate)
+			ForceAttemptHTTP2: true,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("https://" + addr + "/test")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK && resp.ProtoMajor == 2
+	}, time.Second, 10*time.Millisecond, "serves HTTPS with HTTP/2")
+
+	resp, err := client.Get("http://" + redirectAddr + "/test?a=b")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusPermanentRedirect, resp.StatusCode)
+	_, port, _ := net.SplitHostPort(addr)
+	require.Equal(t, "https://127.0.0.1:"+port+"/test?a=b", resp.Header.Get("Location"))
+
+	cancel()
+	require.NoError(t, <-runErr)
+
+	_, err = client.Get("http://" + redirectAddr + "/test")
+	require.Error(t, err, "the redirect listener is shut down with the server")
+}
+
+func TestHTTPSRedirectHandler(t *testing.T) {
+	testCases := []struct {
+		port     string
+		host     string
+		location string
+	}{
+		{port: "443", host: "example.com", location: "https://example.com/a?b=c"},
+		{port: "443", host: "example.com:80", location: "https://example.com/a?b=c"},
+		{port: "8443", host: "example.com:8080", location: "https://example.com:8443/a?b=c"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host+" to "+tc.port, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/a?b=c", nil)
+			r.Host = tc.host
+			w := httptest.NewRecorder()
+
+			httpsRedirectHandler(tc.port).ServeHTTP(w, r)
+
+			require.Equal(t, http.StatusPermanentRedirect, w.Code)
+			require.Equal(t, tc.location, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestServerURL(t *testing.T) {
+	require.Equal(t, "http://localhost:9999", NewServer().url())
+	require.Equal(t, "http://127.0.0.1:8080", NewServer(WithPort("127.0.0.1:8080")).url())
+	require.Equal(t, "https://localhost:8443", NewServer(WithPort(":8443"), WithTLS("cert.pem", "key.pem")).url())
+	require.Equal(t, "https://localhost:8443", NewServer(WithPort(":8443"), WithTLSConfig(&tls.Config{})).url())
+}
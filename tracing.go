@@ -0,0 +1,80 @@
+package fuego
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Tracer traces the requests of a server, see [WithTracer].
+// The github.com/go-fuego/fuego/otel module implements it with OpenTelemetry.
+type Tracer interface {
+	// StartRequest starts the server span of a request, continuing the trace of its headers if any.
+	// The route is the pattern of the route, and the operation ID the one of the OpenAPI spec, possibly empty.
+	// The returned function ends the span with the status code of the response.
+	StartRequest(r *http.Request, route, operationID string) (context.Context, func(status int))
+
+	// StartSpan starts a child span of the span of the context.
+	// The returned function ends the span, recording the error if any.
+	StartSpan(ctx context.Context, name string) (context.Context, func(err error))
+
+	// RecordError records an error transformed by the error handler, and its status code, on the span of the context.
+	RecordError(ctx context.Context, err error, status int)
+}
+
+const contextKeyTracer contextKey = "tracer"
+
+// tracingMiddleware starts the server span of a route.
+// It must be called after the route pattern is stored in the request context.
+func tracingMiddleware(tracer Tracer, next http.Handler, operation *openapi3.Operation) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Read on each request: the operation ID is set after the route is registered.
+		operationID := ""
+		if operation != nil {
+			operationID = operation.OperationID
+		}
+
+		ctx, end := tracer.StartRequest(r, routePattern(r.Context()), operationID)
+		writer := &statusRecorder{ResponseWriter: w}
+		defer func() { end(writer.statusCode()) }()
+
+		next.ServeHTTP(writer, r.WithContext(context.WithValue(ctx, contextKeyTracer, tracer)))
+	})
+}
+
+// startSpan starts a child span of the current span, if tracing is enabled.
+// Otherwise, it returns the context as is and a function that does nothing.
+// The returned function ends the span, recording the error if any.
+func startSpan(ctx context.Context, name string) (context.Context, func(err error)) {
+	tracer, ok := ctx.Value(contextKeyTracer).(Tracer)
+	if !ok {
+		return ctx, func(error) {}
+	}
+	return tracer.StartSpan(ctx, name)
+}
+
+// startRequestSpan works like [startSpan], and returns the request with the span in its context.
+func startRequestSpan(r *http.Request, name string) (*http.Request, func(err error)) {
+	ctx, end := startSpan(r.Context(), name)
+	if ctx == r.Context() {
+		return r, end
+	}
+	return r.WithContext(ctx), end
+}
+
+// recordError records the error, transformed by the error handler, on the server span.
+func recordError(ctx context.Context, err error) {
+	tracer, ok := ctx.Value(contextKeyTracer).(Tracer)
+	if !ok {
+		return
+	}
+
+	status := http.StatusInternalServerError
+	var errorStatus ErrorWithStatus
+	if errors.As(err, &errorStatus) {
+		status = errorStatus.Status()
+	}
+	tracer.RecordError(ctx, err, status)
+}
@@ -0,0 +1,18 @@
+package fuego
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracingDisabled(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	ctx, end := startSpan(r.Context(), "test")
+	require.Equal(t, r.Context(), ctx)
+	end(nil)
+
+	request, _ := startRequestSpan(r, "test")
+	require.Same(t, r, request)
+}
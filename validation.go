@@ -3,6 +3,7 @@ package fuego
 import (
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
@@ -62,8 +63,8 @@ func (e structValidationError) Info() map[string]any {
 var v = validator.New()
 
 func validate(a any) error {
-	_, ok := a.(map[string]any)
-	if ok {
+	// Only structs can be validated (maps, strings... are not).
+	if reflect.Indirect(reflect.ValueOf(a)).Kind() != reflect.Struct {
 		return nil
 	}
 